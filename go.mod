@@ -6,6 +6,8 @@ require (
 	github.com/magefile/mage v1.15.0
 	github.com/rwxrob/bonzai v0.20.10
 	github.com/rwxrob/help v0.7.2
+	golang.org/x/sys v0.34.0
+	golang.org/x/text v0.27.0
 )
 
 require (
@@ -16,6 +18,5 @@ require (
 	github.com/rwxrob/term v0.2.8 // indirect
 	github.com/rwxrob/to v0.11.2 // indirect
 	golang.org/x/crypto v0.7.0 // indirect
-	golang.org/x/sys v0.34.0 // indirect
 	golang.org/x/term v0.7.0 // indirect
 )
@@ -0,0 +1,242 @@
+package zet
+
+import (
+	"math"
+	"sort"
+)
+
+// ShortestPath returns the nodes on a shortest chain of links from the
+// node at from to the node at to, both included, or nil if there is
+// none. Links are only followed forwards when directed.
+func (g *Graph) ShortestPath(from, to int, directed bool) []int {
+	adjacent := g.adjacency(directed)
+	previous := map[int]int{from: -1}
+	queue := []int{from}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		if node == to {
+			var path []int
+			for ; node != -1; node = previous[node] {
+				path = append([]int{node}, path...)
+			}
+			return path
+		}
+		for _, next := range adjacent[node] {
+			if _, ok := previous[next]; !ok {
+				previous[next] = node
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// Degrees returns the number of links into and out of each node.
+func (g *Graph) Degrees() (in, out []int) {
+	in = make([]int, len(g.Nodes))
+	out = make([]int, len(g.Nodes))
+	for _, edge := range g.Edges {
+		out[edge.From]++
+		in[edge.To]++
+	}
+	return in, out
+}
+
+// PageRank returns the PageRank of each node. Nodes without outgoing
+// links spread their rank evenly over the whole graph.
+func (g *Graph) PageRank() []float64 {
+	const damping, iterations, tolerance = 0.85, 100, 1e-9
+
+	n := len(g.Nodes)
+	if n == 0 {
+		return nil
+	}
+	_, out := g.Degrees()
+
+	rank := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+	for iter := 0; iter < iterations; iter++ {
+		dangling := 0.0
+		for i, r := range rank {
+			if out[i] == 0 {
+				dangling += r
+			}
+		}
+
+		next := make([]float64, n)
+		base := (1-damping)/float64(n) + damping*dangling/float64(n)
+		for i := range next {
+			next[i] = base
+		}
+		for _, edge := range g.Edges {
+			next[edge.To] += damping * rank[edge.From] / float64(out[edge.From])
+		}
+
+		delta := 0.0
+		for i := range rank {
+			delta += math.Abs(next[i] - rank[i])
+		}
+		rank = next
+		if delta < tolerance {
+			break
+		}
+	}
+	return rank
+}
+
+// Components returns the groups of nodes connected by links in either
+// direction, largest first.
+func (g *Graph) Components() [][]int {
+	labels := make([]int, len(g.Nodes))
+	for i := range labels {
+		labels[i] = -1
+	}
+	for i := range g.Nodes {
+		if labels[i] >= 0 {
+			continue
+		}
+		for node := range g.Distances(i, false) {
+			labels[node] = i
+		}
+	}
+	return groupLabels(labels)
+}
+
+// Communities returns groups of densely linked nodes, largest first.
+// Starting from every node on its own, nodes are repeatedly moved to
+// the neighbouring group that most increases modularity, treating links
+// as undirected, until no move helps. Ties go to the lowest group so
+// that the result is deterministic.
+func (g *Graph) Communities() [][]int {
+	n := len(g.Nodes)
+	neighbours := make([]map[int]bool, n)
+	for i := range neighbours {
+		neighbours[i] = make(map[int]bool)
+	}
+	edges := 0
+	for _, edge := range g.Edges {
+		if !neighbours[edge.From][edge.To] {
+			neighbours[edge.From][edge.To] = true
+			neighbours[edge.To][edge.From] = true
+			edges++
+		}
+	}
+
+	labels := make([]int, n)
+	totals := make([]float64, n)
+	for i := range labels {
+		labels[i] = i
+		totals[i] = float64(len(neighbours[i]))
+	}
+	if edges == 0 {
+		return groupLabels(labels)
+	}
+
+	for moved, iter := true, 0; moved && iter < 100; iter++ {
+		moved = false
+		for node := 0; node < n; node++ {
+			degree := float64(len(neighbours[node]))
+			if degree == 0 {
+				continue
+			}
+			current := labels[node]
+			totals[current] -= degree
+
+			links := map[int]float64{current: 0}
+			for neighbour := range neighbours[node] {
+				links[labels[neighbour]]++
+			}
+			best, bestGain := current, links[current]-totals[current]*degree/float64(2*edges)
+			for label, count := range links {
+				gain := count - totals[label]*degree/float64(2*edges)
+				if gain > bestGain+1e-12 || math.Abs(gain-bestGain) <= 1e-12 && label < best {
+					best, bestGain = label, gain
+				}
+			}
+
+			totals[best] += degree
+			if best != current {
+				labels[node] = best
+				moved = true
+			}
+		}
+	}
+	return groupLabels(labels)
+}
+
+func groupLabels(labels []int) [][]int {
+	groups := make(map[int][]int)
+	var order []int
+	for node, label := range labels {
+		if _, ok := groups[label]; !ok {
+			order = append(order, label)
+		}
+		groups[label] = append(groups[label], node)
+	}
+
+	result := make([][]int, 0, len(order))
+	for _, label := range order {
+		result = append(result, groups[label])
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return len(result[i]) > len(result[j])
+	})
+	return result
+}
+
+// Bridges returns the nodes whose removal splits the group of nodes
+// they are connected to, treating links as undirected, in node order.
+func (g *Graph) Bridges() []int {
+	adjacent := g.adjacency(false)
+	n := len(g.Nodes)
+	discovered := make([]int, n)
+	low := make([]int, n)
+	for i := range discovered {
+		discovered[i] = -1
+	}
+
+	cut := make([]bool, n)
+	time := 0
+	var visit func(node, parent int)
+	visit = func(node, parent int) {
+		discovered[node] = time
+		low[node] = time
+		time++
+
+		children := 0
+		for _, next := range adjacent[node] {
+			if next == parent {
+				continue
+			}
+			if discovered[next] >= 0 {
+				low[node] = min(low[node], discovered[next])
+				continue
+			}
+			children++
+			visit(next, node)
+			low[node] = min(low[node], low[next])
+			if parent >= 0 && low[next] >= discovered[node] {
+				cut[node] = true
+			}
+		}
+		if parent < 0 && children > 1 {
+			cut[node] = true
+		}
+	}
+	for i := range g.Nodes {
+		if discovered[i] < 0 {
+			visit(i, -1)
+		}
+	}
+
+	var bridges []int
+	for i, isCut := range cut {
+		if isCut {
+			bridges = append(bridges, i)
+		}
+	}
+	return bridges
+}
@@ -0,0 +1,109 @@
+package zet_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+// linkedNotes returns notes named by the keys of links, each linking to
+// the notes listed for it.
+func linkedNotes(names []string, links map[string][]string) []*zet.Note {
+	var notes []*zet.Note
+	for _, name := range names {
+		body := "# " + name + "\n"
+		for _, target := range links[name] {
+			body += "[[" + target + "]]\n"
+		}
+		notes = append(notes, &zet.Note{Title: name, Path: "/tmp/" + name + ".md", Body: body})
+	}
+	return notes
+}
+
+func titles(g *zet.Graph, nodes []int) string {
+	var names []string
+	for _, i := range nodes {
+		names = append(names, g.Nodes[i].Title)
+	}
+	return fmt.Sprint(names)
+}
+
+func TestShortestPath(t *testing.T) {
+	g := zet.BuildGraph(linkedNotes(
+		[]string{"A", "B", "C", "D", "E"},
+		map[string][]string{"A": {"B", "C"}, "B": {"D"}, "C": {"D"}, "D": {"E"}},
+	))
+
+	tests := []struct {
+		name     string
+		from, to int
+		directed bool
+		expected string
+	}{
+		{"forwards", 0, 4, true, "[A B D E]"},
+		{"same note", 2, 2, true, "[C]"},
+		{"backwards directed", 4, 0, true, "[]"},
+		{"backwards undirected", 4, 0, false, "[E D B A]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := titles(g, g.ShortestPath(tt.from, tt.to, tt.directed))
+			if got != tt.expected {
+				t.Errorf("ShortestPath() = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDegreesAndPageRank(t *testing.T) {
+	g := zet.BuildGraph(linkedNotes(
+		[]string{"Hub", "A", "B", "C"},
+		map[string][]string{"A": {"Hub"}, "B": {"Hub"}, "C": {"Hub", "A"}},
+	))
+
+	in, out := g.Degrees()
+	if fmt.Sprint(in) != "[3 1 0 0]" || fmt.Sprint(out) != "[0 1 1 2]" {
+		t.Errorf("Degrees() = %v, %v", in, out)
+	}
+
+	rank := g.PageRank()
+	sum := 0.0
+	for i, r := range rank {
+		sum += r
+		if i > 0 && r >= rank[0] {
+			t.Errorf("PageRank() of %s = %f, not below hub %f", g.Nodes[i].Title, r, rank[0])
+		}
+	}
+	if sum < 0.999 || sum > 1.001 {
+		t.Errorf("PageRank() sums to %f, want 1", sum)
+	}
+}
+
+func TestComponentsAndCommunities(t *testing.T) {
+	// Two triangles joined through Bridge, plus a lone note
+	g := zet.BuildGraph(linkedNotes(
+		[]string{"A1", "A2", "A3", "Bridge", "B1", "B2", "B3", "Lone"},
+		map[string][]string{
+			"A1": {"A2", "A3"}, "A2": {"A3"}, "A3": {"Bridge"},
+			"Bridge": {"B1"}, "B1": {"B2", "B3"}, "B2": {"B3"},
+		},
+	))
+
+	components := g.Components()
+	if len(components) != 2 || len(components[0]) != 7 || titles(g, components[1]) != "[Lone]" {
+		t.Errorf("Components() = %v", components)
+	}
+
+	communities := g.Communities()
+	if len(communities) != 3 {
+		t.Fatalf("Communities() = %v, want 3 groups", communities)
+	}
+	if got := titles(g, communities[0]); got != "[A1 A2 A3 Bridge]" && got != "[Bridge B1 B2 B3]" {
+		t.Errorf("Communities()[0] = %s, want one triangle with the bridge", got)
+	}
+
+	if got := titles(g, g.Bridges()); got != "[A3 Bridge B1]" {
+		t.Errorf("Bridges() = %s, want [A3 Bridge B1]", got)
+	}
+}
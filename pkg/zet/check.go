@@ -0,0 +1,43 @@
+package zet
+
+import "fmt"
+
+type BrokenLink struct {
+	Note   *Note
+	Link   Link
+	Reason string
+}
+
+func (b BrokenLink) String() string {
+	return fmt.Sprintf("%s:%d: %s", b.Note.Title, b.Link.Line, b.Reason)
+}
+
+// CheckLinks returns the links in notes whose target note, heading or
+// block does not exist.
+func CheckLinks(notes []*Note) []BrokenLink {
+	r := newResolver(notes)
+
+	var broken []BrokenLink
+	for _, note := range notes {
+		for _, link := range ParseLinks(note.Body) {
+			target := r.lookup(link.Target)
+			if target == nil && link.Target == "" {
+				target = note
+			}
+
+			switch {
+			case target == nil:
+				broken = append(broken, BrokenLink{note, link, fmt.Sprintf("no note %q", link.Target)})
+			case link.Block != "":
+				if _, ok := AnchorLine(target.Body, link); !ok {
+					broken = append(broken, BrokenLink{note, link, fmt.Sprintf("no block ^%s in %q", link.Block, target.Title)})
+				}
+			case link.Heading != "":
+				if _, ok := AnchorLine(target.Body, link); !ok {
+					broken = append(broken, BrokenLink{note, link, fmt.Sprintf("no heading %q in %q", link.Heading, target.Title)})
+				}
+			}
+		}
+	}
+	return broken
+}
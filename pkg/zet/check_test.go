@@ -0,0 +1,33 @@
+package zet_test
+
+import (
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestCheckLinks(t *testing.T) {
+	notes := []*zet.Note{
+		{Title: "Source", Path: "/tmp/Source.md", Body: "[[Target#Part]] [[Target#Gone]]\n[[Target#^b1]] [[Target#^b2]]\n[[Nowhere]] [[#Local]] [[#Missing]]\n## Local"},
+		{Title: "Target", Path: "/tmp/Target.md", Body: "## Part\ntext ^b1"},
+		{Title: "C", Path: "/tmp/C.md", Body: "[[C++]]"},
+	}
+
+	broken := zet.CheckLinks(notes)
+
+	expected := []string{
+		`Source:1: no heading "Gone" in "Target"`,
+		`Source:2: no block ^b2 in "Target"`,
+		`Source:3: no note "Nowhere"`,
+		`Source:3: no heading "Missing" in "Source"`,
+		`C:1: no note "C++"`,
+	}
+	if len(broken) != len(expected) {
+		t.Fatalf("CheckLinks() = %v, want %v", broken, expected)
+	}
+	for i := range broken {
+		if broken[i].String() != expected[i] {
+			t.Errorf("CheckLinks()[%d] = %q, want %q", i, broken[i].String(), expected[i])
+		}
+	}
+}
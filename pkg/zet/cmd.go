@@ -1,16 +1,33 @@
 package zet
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	bonzai "github.com/rwxrob/bonzai/z"
 	"github.com/rwxrob/help"
 )
 
 var Cmd = &bonzai.Cmd{
-	Name:     "zet",
-	Commands: []*bonzai.Cmd{help.Cmd, listCmd, deleteCmd, newCmd, renderCmd},
+	Name: "zet",
+	Commands: []*bonzai.Cmd{
+		help.Cmd, listCmd, deleteCmd, newCmd, renderCmd, searchCmd, backlinksCmd,
+		daemonCmd, watchCmd, configCmd, appendCmd, prependCmd, captureCmd,
+		catCmd, pathCmd, exportCmd, checkCmd, graphCmd,
+		pathBetweenCmd, hubsCmd, clustersCmd, bridgesCmd,
+		neighborsCmd, mentionsCmd, relatedCmd, mergeCmd,
+		splitCmd, tasksCmd, queryCmd, savedCmd,
+		recentCmd, pinCmd, unpinCmd, pinsCmd,
+	},
 	Call: func(cmd *bonzai.Cmd, args ...string) error {
 		search := strings.Join(args, " ")
 		return OpenNote(search)
@@ -45,28 +62,51 @@ var deleteCmd = &bonzai.Cmd{
 var newCmd = &bonzai.Cmd{
 	Name: "new",
 	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		flags, args := parseArgs(args)
 		title := strings.Join(args, " ")
 		if title == "" {
 			return fmt.Errorf("title required")
 		}
 
+		if _, ok := flags["stdin"]; ok {
+			content, err := readContent(nil)
+			if err != nil {
+				return err
+			}
+			path, err := AddToNote(title, content, InsertOptions{})
+			if err != nil {
+				return err
+			}
+			fmt.Println(path)
+			return nil
+		}
+
 		path, err := CreateOrEditNote(title)
+		var collision *CollisionError
+		if errors.As(err, &collision) {
+			path, err = ResolveCollision(collision)
+		}
 		if err != nil {
 			return err
 		}
 
-		editor := GetEditor()
-		return bonzai.SysExec(editor, path)
+		return EditAndFinish(path, 0, 0)
 	},
 }
 
 var listCmd = &bonzai.Cmd{
 	Name: "list",
 	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		flags, _ := parseArgs(args, "where")
+
 		notes, err := ListNotes()
 		if err != nil {
 			return err
 		}
+		notes, err = whereNotes(flags, notes)
+		if err != nil {
+			return err
+		}
 
 		for _, note := range notes {
 			fmt.Println(note.Title)
@@ -83,3 +123,1140 @@ var renderCmd = &bonzai.Cmd{
 		return RenderNote(search)
 	},
 }
+
+var searchCmd = &bonzai.Cmd{
+	Name: "search",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		flags, args := parseArgs(args, "where")
+		query := strings.Join(args, " ")
+		if query == "" {
+			return fmt.Errorf("query required")
+		}
+
+		notes, err := SearchNotes(query)
+		if err != nil {
+			return err
+		}
+		notes, err = whereNotes(flags, notes)
+		if err != nil {
+			return err
+		}
+
+		hits := SearchHits(notes, query)
+		if _, ok := flags["open"]; ok {
+			hit, err := FindSearchHit(hits, "")
+			if err != nil {
+				return err
+			}
+			return EditAndFinish(hit.Note.Path, hit.Line, hit.Col)
+		}
+
+		for _, hit := range hits {
+			fmt.Printf("%s:%d: %s\n", hit.Note.Title, hit.Line, strings.TrimSpace(hit.Text))
+		}
+
+		return nil
+	},
+}
+
+var backlinksCmd = &bonzai.Cmd{
+	Name: "backlinks",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		search := strings.Join(args, " ")
+
+		notes, err := ListNotes()
+		if err != nil {
+			return err
+		}
+
+		note, err := FindNote(notes, search)
+		if err != nil {
+			return err
+		}
+
+		backlinks, err := NoteBacklinks(note)
+		if err != nil {
+			return err
+		}
+
+		for _, backlink := range backlinks {
+			fmt.Println(backlink.Title)
+		}
+
+		return nil
+	},
+}
+
+var daemonCmd = &bonzai.Cmd{
+	Name:     "daemon",
+	Commands: []*bonzai.Cmd{daemonStopCmd, daemonStatusCmd},
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		dir, err := GetZetDir()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Serving %s @ %s\n", dir, DaemonSocket(dir))
+		return NewDaemon(dir).Run()
+	},
+}
+
+var daemonStopCmd = &bonzai.Cmd{
+	Name: "stop",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		dir, err := GetZetDir()
+		if err != nil {
+			return err
+		}
+
+		return StopDaemon(dir)
+	},
+}
+
+var daemonStatusCmd = &bonzai.Cmd{
+	Name: "status",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		dir, err := GetZetDir()
+		if err != nil {
+			return err
+		}
+
+		if !DaemonRunning(dir) {
+			return ErrDaemonNotRunning
+		}
+
+		fmt.Printf("Running @ %s\n", DaemonSocket(dir))
+		return nil
+	},
+}
+
+var watchCmd = &bonzai.Cmd{
+	Name: "watch",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		dir, err := GetZetDir()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Watching %s\n", dir)
+		return WatchHooks(dir, Interrupted())
+	},
+}
+
+var configCmd = &bonzai.Cmd{
+	Name: "config",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		dir, err := GetZetDir()
+		if err != nil {
+			return err
+		}
+
+		switch len(args) {
+		case 0:
+			options, err := ReadOptions(dir)
+			if err != nil {
+				return err
+			}
+			var names []string
+			for name := range options {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Printf("%s = %s\n", name, options[name])
+			}
+			return nil
+		case 1:
+			fmt.Println(GetOption(dir, args[0]))
+			return nil
+		default:
+			return SetOption(dir, args[0], strings.Join(args[1:], " "))
+		}
+	},
+}
+
+var catCmd = &bonzai.Cmd{
+	Name: "cat",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		flags, args := parseArgs(args)
+
+		note, err := selectNote(flags, strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+
+		body := note.Body
+		if _, ok := flags["expand"]; ok {
+			notes, err := ListNotes()
+			if err != nil {
+				return err
+			}
+			body = ExpandNote(notes, note)
+		}
+		if _, ok := flags["strip-frontmatter"]; ok {
+			body = StripFrontmatter(body)
+		}
+
+		fmt.Print(body)
+		return nil
+	},
+}
+
+var pathCmd = &bonzai.Cmd{
+	Name: "path",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		flags, args := parseArgs(args)
+
+		note, err := selectNote(flags, strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+
+		path, err := filepath.Abs(note.Path)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(path)
+		return nil
+	},
+}
+
+var exportCmd = &bonzai.Cmd{
+	Name: "export",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		flags, args := parseArgs(args, "where")
+		if len(args) != 1 {
+			return fmt.Errorf("output directory required")
+		}
+
+		all, err := ListNotes()
+		if err != nil {
+			return err
+		}
+		notes, err := whereNotes(flags, all)
+		if err != nil {
+			return err
+		}
+
+		err = ExportNotes(all, notes, args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Exported %d notes @ %s\n", len(notes), args[0])
+		return nil
+	},
+}
+
+var checkCmd = &bonzai.Cmd{
+	Name: "check",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		notes, err := ListNotes()
+		if err != nil {
+			return err
+		}
+
+		broken := CheckLinks(notes)
+		for _, link := range broken {
+			fmt.Println(link)
+		}
+
+		if len(broken) > 0 {
+			return fmt.Errorf("%d broken links", len(broken))
+		}
+		return nil
+	},
+}
+
+var graphCmd = &bonzai.Cmd{
+	Name: "graph",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		flags, _ := parseArgs(args, "format", "tag", "query", "around", "depth", "where")
+
+		notes, err := ListNotes()
+		if err != nil {
+			return err
+		}
+		g := BuildGraph(notes)
+
+		if around, ok := flags["around"]; ok {
+			note, err := FindNote(notes, around)
+			if err != nil {
+				return err
+			}
+			depth := 1
+			if value, ok := flags["depth"]; ok {
+				depth, err = strconv.Atoi(value)
+				if err != nil || depth < 0 {
+					return fmt.Errorf("invalid depth: %s", value)
+				}
+			}
+			g = g.Around(note, depth)
+		}
+
+		if tag, ok := flags["tag"]; ok {
+			g = g.Filter(func(note *Note) bool { return HasTag(note, tag) })
+		}
+
+		if query, ok := flags["query"]; ok {
+			matches, err := SearchNotes(query)
+			if err != nil {
+				return err
+			}
+			matched := make(map[string]bool)
+			for _, note := range matches {
+				matched[note.Path] = true
+			}
+			g = g.Filter(func(note *Note) bool { return matched[note.Path] })
+		}
+
+		if _, ok := flags["where"]; ok {
+			matches, err := whereNotes(flags, notes)
+			if err != nil {
+				return err
+			}
+			matched := make(map[string]bool)
+			for _, note := range matches {
+				matched[note.Path] = true
+			}
+			g = g.Filter(func(note *Note) bool { return matched[note.Path] })
+		}
+
+		format := GraphFormat(flags["format"])
+		if format == "" {
+			format = FormatDOT
+		}
+		_, color := flags["color"]
+		return WriteGraph(os.Stdout, g, format, color)
+	},
+}
+
+var pathBetweenCmd = &bonzai.Cmd{
+	Name: "path-between",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		flags, args := parseArgs(args)
+		if len(args) != 2 {
+			return fmt.Errorf("two notes required")
+		}
+
+		notes, err := ListNotes()
+		if err != nil {
+			return err
+		}
+		from, err := FindNote(notes, args[0])
+		if err != nil {
+			return err
+		}
+		to, err := FindNote(notes, args[1])
+		if err != nil {
+			return err
+		}
+
+		g := BuildGraph(notes)
+		_, undirected := flags["undirected"]
+		path := g.ShortestPath(g.indexOf(from), g.indexOf(to), !undirected)
+		if path == nil {
+			return fmt.Errorf("no path from %s to %s", from.Title, to.Title)
+		}
+
+		for _, i := range path {
+			fmt.Println(g.Nodes[i].Title)
+		}
+		return nil
+	},
+}
+
+var hubsCmd = &bonzai.Cmd{
+	Name: "hubs",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		flags, _ := parseArgs(args, "by", "top")
+
+		top, err := topFlag(flags, 10)
+		if err != nil {
+			return err
+		}
+
+		notes, err := ListNotes()
+		if err != nil {
+			return err
+		}
+		g := BuildGraph(notes)
+
+		var scores []float64
+		var format string
+		switch flags["by"] {
+		case "", "degree":
+			in, out := g.Degrees()
+			for i := range g.Nodes {
+				scores = append(scores, float64(in[i]+out[i]))
+			}
+			format = "%4.0f  %s\n"
+		case "pagerank":
+			scores = g.PageRank()
+			format = "%.4f  %s\n"
+		default:
+			return fmt.Errorf("unknown ranking %q (want degree or pagerank)", flags["by"])
+		}
+
+		order := make([]int, len(g.Nodes))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return scores[order[a]] > scores[order[b]]
+		})
+
+		for _, i := range order[:min(top, len(order))] {
+			fmt.Printf(format, scores[i], g.Nodes[i].Title)
+		}
+		return nil
+	},
+}
+
+var clustersCmd = &bonzai.Cmd{
+	Name: "clusters",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		flags, _ := parseArgs(args)
+
+		notes, err := ListNotes()
+		if err != nil {
+			return err
+		}
+		g := BuildGraph(notes)
+
+		groups := g.Components()
+		if _, ok := flags["communities"]; ok {
+			groups = g.Communities()
+		}
+
+		for i, group := range groups {
+			fmt.Printf("Cluster %d (%d notes)\n", i+1, len(group))
+			for _, node := range group {
+				fmt.Printf("  %s\n", g.Nodes[node].Title)
+			}
+		}
+		return nil
+	},
+}
+
+var bridgesCmd = &bonzai.Cmd{
+	Name: "bridges",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		notes, err := ListNotes()
+		if err != nil {
+			return err
+		}
+		g := BuildGraph(notes)
+
+		for _, i := range g.Bridges() {
+			fmt.Println(g.Nodes[i].Title)
+		}
+		return nil
+	},
+}
+
+var neighborsCmd = &bonzai.Cmd{
+	Name: "neighbors",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		flags, args := parseArgs(args, "depth", "file")
+
+		depth := 2
+		if value, ok := flags["depth"]; ok {
+			var err error
+			depth, err = strconv.Atoi(value)
+			if err != nil || depth < 0 {
+				return fmt.Errorf("invalid depth: %s", value)
+			}
+		}
+
+		notes, err := ListNotes()
+		if err != nil {
+			return err
+		}
+
+		var note *Note
+		if file, ok := flags["file"]; ok {
+			for _, n := range notes {
+				if filepath.Clean(n.Path) == filepath.Clean(file) {
+					note = n
+				}
+			}
+			if note == nil {
+				return fmt.Errorf("no note at %s", file)
+			}
+		} else {
+			note, err = selectNote(flags, strings.Join(args, " "))
+			if err != nil {
+				return err
+			}
+		}
+
+		g := BuildGraph(notes)
+		fmt.Print(g.NeighborTree(g.indexOf(note), depth))
+		return nil
+	},
+}
+
+var mentionsCmd = &bonzai.Cmd{
+	Name: "mentions",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		flags, args := parseArgs(args)
+
+		target, err := selectNote(flags, strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+		notes, err := ListNotes()
+		if err != nil {
+			return err
+		}
+
+		mentions := FindMentions(notes, target)
+		if _, ok := flags["link"]; !ok {
+			for _, m := range mentions {
+				lines := strings.Split(m.Note.Body, "\n")
+				fmt.Printf("%s:%d: %s\n", m.Note.Title, m.Line, strings.TrimSpace(lines[m.Line-1]))
+			}
+			return nil
+		}
+
+		var order []*Note
+		accepted := make(map[*Note][]Mention)
+		all := false
+	ask:
+		for _, m := range mentions {
+			if !all {
+				lines := strings.Split(m.Note.Body, "\n")
+				fmt.Fprintf(os.Stderr, "%s:%d: %s\n", m.Note.Title, m.Line, strings.TrimSpace(lines[m.Line-1]))
+				choice, err := Choose(fmt.Sprintf("Link %q?", m.Text), "yes", "no", "all", "quit")
+				if err != nil {
+					return err
+				}
+				switch choice {
+				case 1:
+					continue
+				case 2:
+					all = true
+				case 3:
+					break ask
+				}
+			}
+			if _, ok := accepted[m.Note]; !ok {
+				order = append(order, m.Note)
+			}
+			accepted[m.Note] = append(accepted[m.Note], m)
+		}
+
+		count := 0
+		for _, note := range order {
+			err := WriteMentionLinks(note, accepted[note], target)
+			if err != nil {
+				return err
+			}
+			count += len(accepted[note])
+		}
+
+		fmt.Printf("Linked %d mentions of %s\n", count, target.Title)
+		return nil
+	},
+}
+
+var relatedCmd = &bonzai.Cmd{
+	Name: "related",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		flags, args := parseArgs(args, "top")
+
+		top, err := topFlag(flags, 10)
+		if err != nil {
+			return err
+		}
+
+		note, err := selectNote(flags, strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+		notes, err := ListNotes()
+		if err != nil {
+			return err
+		}
+
+		_, linked := flags["linked"]
+		for _, related := range RelatedNotes(notes, note, top, linked) {
+			fmt.Printf("%.3f  %s\n", related.Score, related.Note.Title)
+		}
+		return nil
+	},
+}
+
+var mergeCmd = &bonzai.Cmd{
+	Name: "merge",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		flags, args := parseArgs(args)
+		if len(args) != 2 {
+			return fmt.Errorf("source and target notes required")
+		}
+
+		source, err := selectNote(flags, args[0])
+		if err != nil {
+			return err
+		}
+		target, err := selectNote(flags, args[1])
+		if err != nil {
+			return err
+		}
+		if source.Path == target.Path {
+			return fmt.Errorf("cannot merge %s into itself", source.Title)
+		}
+
+		notes, err := ListNotes()
+		if err != nil {
+			return err
+		}
+
+		if _, ok := flags["dry-run"]; ok {
+			for _, change := range PlanMerge(notes, source, target) {
+				fmt.Print(UnifiedDiff(filepath.Base(change.Path), change.Before, change.After))
+			}
+			return nil
+		}
+
+		err = MergeNotes(notes, source, target)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Merged %s into %s @ %s\n", source.Title, target.Title, target.Path)
+		return nil
+	},
+}
+
+var splitCmd = &bonzai.Cmd{
+	Name: "split",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		flags, args := parseArgs(args, "level")
+
+		level := 2
+		if value, ok := flags["level"]; ok {
+			var err error
+			level, err = strconv.Atoi(value)
+			if err != nil || level < 1 || level > 6 {
+				return fmt.Errorf("invalid level: %s", value)
+			}
+		}
+
+		note, err := selectNote(flags, strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+		notes, err := ListNotes()
+		if err != nil {
+			return err
+		}
+
+		var selected []Section
+		all := false
+	ask:
+		for _, section := range ParseSections(note.Body, level) {
+			title := section.Heading.Text
+			if existing := LookupNote(notes, title); existing != nil {
+				fmt.Fprintf(os.Stderr, "Skipping %q: note %q exists\n", title, existing.Title)
+				continue
+			}
+			if !all {
+				choice, err := Choose(fmt.Sprintf("Split %q (%d lines)?", title, section.End-section.Start), "yes", "no", "all", "quit")
+				if err != nil {
+					return err
+				}
+				switch choice {
+				case 1:
+					continue
+				case 2:
+					all = true
+				case 3:
+					break ask
+				}
+			}
+			selected = append(selected, section)
+		}
+		if len(selected) == 0 {
+			return nil
+		}
+
+		_, transclude := flags["transclude"]
+		err = SplitNote(notes, note, selected, transclude)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Split %d sections out of %s\n", len(selected), note.Title)
+		return nil
+	},
+}
+
+var tasksCmd = &bonzai.Cmd{
+	Name:     "tasks",
+	Commands: []*bonzai.Cmd{tasksToggleCmd},
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		flags, _ := parseArgs(args, "tag", "note", "due")
+
+		notes, filter, err := taskFilter(flags)
+		if err != nil {
+			return err
+		}
+		tasks := FilterTasks(notes, filter)
+
+		if _, ok := flags["json"]; ok {
+			if tasks == nil {
+				tasks = []Task{}
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(tasks)
+		}
+
+		for _, task := range tasks {
+			fmt.Println(FormatTask(task))
+		}
+		return nil
+	},
+}
+
+var tasksToggleCmd = &bonzai.Cmd{
+	Name: "toggle",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		flags, args := parseArgs(args, "tag", "note", "due")
+
+		notes, filter, err := taskFilter(flags)
+		if err != nil {
+			return err
+		}
+
+		task, err := FindTask(FilterTasks(notes, filter), strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+
+		err = ToggleTask(*task)
+		if err != nil {
+			return err
+		}
+
+		task.Done = !task.Done
+		fmt.Println(FormatTask(*task))
+		return nil
+	},
+}
+
+// taskFilter returns the notes and filter for the task flags --tag,
+// --note, --due and --done.
+func taskFilter(flags map[string]string) ([]*Note, TaskFilter, error) {
+	notes, err := ListNotes()
+	if err != nil {
+		return nil, TaskFilter{}, err
+	}
+
+	filter := TaskFilter{Tag: flags["tag"]}
+	_, filter.Done = flags["done"]
+
+	if search, ok := flags["note"]; ok {
+		filter.Note, err = FindNote(notes, search)
+		if err != nil {
+			return nil, TaskFilter{}, err
+		}
+	}
+	if due, ok := flags["due"]; ok {
+		filter.Due, err = ParseDueDate(due, time.Now())
+		if err != nil {
+			return nil, TaskFilter{}, err
+		}
+	}
+	return notes, filter, nil
+}
+
+var queryCmd = &bonzai.Cmd{
+	Name: "query",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		query, err := ParseQuery(strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+
+		notes, err := ListNotes()
+		if err != nil {
+			return err
+		}
+
+		for _, note := range query.Filter(notes) {
+			fmt.Println(note.Title)
+		}
+		return nil
+	},
+}
+
+var savedCmd = &bonzai.Cmd{
+	Name:     "saved",
+	Commands: []*bonzai.Cmd{savedAddCmd, savedListCmd, savedRunCmd, savedRemoveCmd},
+}
+
+var savedAddCmd = &bonzai.Cmd{
+	Name: "add",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		if len(args) < 2 {
+			return fmt.Errorf("name and query required")
+		}
+
+		dir, err := GetZetDir()
+		if err != nil {
+			return err
+		}
+		return SaveQuery(dir, args[0], strings.Join(args[1:], " "))
+	},
+}
+
+var savedListCmd = &bonzai.Cmd{
+	Name: "list",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		dir, err := GetZetDir()
+		if err != nil {
+			return err
+		}
+
+		saved, err := ReadSavedQueries(dir)
+		if err != nil {
+			return err
+		}
+		var names []string
+		for name := range saved {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("%s = %s\n", name, saved[name])
+		}
+		return nil
+	},
+}
+
+var savedRunCmd = &bonzai.Cmd{
+	Name: "run",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		dir, err := GetZetDir()
+		if err != nil {
+			return err
+		}
+
+		query, err := SavedQuery(dir, strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+
+		notes, err := ListNotes()
+		if err != nil {
+			return err
+		}
+		for _, note := range query.Filter(notes) {
+			fmt.Println(note.Title)
+		}
+		return nil
+	},
+}
+
+var savedRemoveCmd = &bonzai.Cmd{
+	Name: "remove",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		dir, err := GetZetDir()
+		if err != nil {
+			return err
+		}
+		return SaveQuery(dir, strings.Join(args, " "), "")
+	},
+}
+
+var recentCmd = &bonzai.Cmd{
+	Name: "recent",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		n := 10
+		if len(args) > 0 {
+			var err error
+			n, err = strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid count %q", args[0])
+			}
+		}
+
+		dir, err := GetZetDir()
+		if err != nil {
+			return err
+		}
+		notes, err := ListNotes()
+		if err != nil {
+			return err
+		}
+
+		recent, err := RecentNotes(dir, notes, n)
+		if err != nil {
+			return err
+		}
+		for _, note := range recent {
+			fmt.Println(note.Title)
+		}
+		return nil
+	},
+}
+
+var pinCmd = &bonzai.Cmd{
+	Name: "pin",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		flags, args := parseArgs(args)
+
+		note, err := selectNote(flags, strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+		return PinNote(note)
+	},
+}
+
+var unpinCmd = &bonzai.Cmd{
+	Name: "unpin",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		pinned, err := listPinned()
+		if err != nil {
+			return err
+		}
+		if len(pinned) == 0 {
+			return fmt.Errorf("no pinned notes")
+		}
+
+		note, err := FindNote(pinned, strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+		return UnpinNote(note)
+	},
+}
+
+var pinsCmd = &bonzai.Cmd{
+	Name: "pins",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		flags, _ := parseArgs(args, "open")
+
+		pinned, err := listPinned()
+		if err != nil {
+			return err
+		}
+
+		if value, ok := flags["open"]; ok {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > len(pinned) {
+				return fmt.Errorf("no pin %q (have %d)", value, len(pinned))
+			}
+			return EditAndFinish(pinned[n-1].Path, 0, 0)
+		}
+
+		for i, note := range pinned {
+			fmt.Printf("%d. %s\n", i+1, note.Title)
+		}
+		return nil
+	},
+}
+
+// listPinned returns the pinned notes of the vault.
+func listPinned() ([]*Note, error) {
+	dir, err := GetZetDir()
+	if err != nil {
+		return nil, err
+	}
+	notes, err := ListNotes()
+	if err != nil {
+		return nil, err
+	}
+	return PinnedNotes(dir, notes)
+}
+
+// whereNotes returns the notes matching the --where query, which is
+// evaluated against the whole vault so that links resolve, or notes
+// unchanged without one.
+func whereNotes(flags map[string]string, notes []*Note) ([]*Note, error) {
+	expr, ok := flags["where"]
+	if !ok {
+		return notes, nil
+	}
+	query, err := ParseQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := ListNotes()
+	if err != nil {
+		return nil, err
+	}
+	matched := make(map[string]bool)
+	for _, note := range query.Filter(all) {
+		matched[note.Path] = true
+	}
+
+	var result []*Note
+	for _, note := range notes {
+		if matched[note.Path] {
+			result = append(result, note)
+		}
+	}
+	return result, nil
+}
+
+// topFlag returns the value of --top, or fallback if it is not given.
+func topFlag(flags map[string]string, fallback int) (int, error) {
+	value, ok := flags["top"]
+	if !ok {
+		return fallback, nil
+	}
+	top, err := strconv.Atoi(value)
+	if err != nil || top < 1 {
+		return 0, fmt.Errorf("invalid top: %s", value)
+	}
+	return top, nil
+}
+
+// selectNote picks a note matching search with fzf, or without asking
+// when --exact (title or filename) or --first (best fuzzy match) is
+// given.
+func selectNote(flags map[string]string, search string) (*Note, error) {
+	notes, err := ListNotes()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := flags["exact"]; ok {
+		return FindNoteExact(notes, search)
+	}
+	if _, ok := flags["first"]; ok {
+		return FindFirstNote(notes, search)
+	}
+	return FindNote(notes, search)
+}
+
+// parseArgs separates --flag and --flag=value arguments from the rest.
+// Flags named in valued take the following argument as their value
+// when it is not given with "=". Everything after "--" is left as is.
+func parseArgs(args []string, valued ...string) (map[string]string, []string) {
+	flags := make(map[string]string)
+	var rest []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "--") || len(arg) == 2 {
+			rest = append(rest, arg)
+			continue
+		}
+
+		name, value, ok := strings.Cut(arg[2:], "=")
+		if !ok && slices.Contains(valued, name) && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		flags[name] = value
+	}
+
+	return flags, rest
+}
+
+var appendCmd = &bonzai.Cmd{
+	Name: "append",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		return addCall(false, args)
+	},
+}
+
+var prependCmd = &bonzai.Cmd{
+	Name: "prepend",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		return addCall(true, args)
+	},
+}
+
+// addCall handles "append|prepend <title> [--section H] [--timestamp]
+// [-- text...]", reading the content from stdin when no text is given.
+func addCall(prepend bool, args []string) error {
+	title, text, _ := slicesCut(args, "--")
+	flags, title := parseArgs(title, "section")
+	if len(title) == 0 {
+		return fmt.Errorf("title required")
+	}
+
+	content, err := readContent(text)
+	if err != nil {
+		return err
+	}
+
+	opts := InsertOptions{Prepend: prepend, Section: flags["section"]}
+	if _, ok := flags["timestamp"]; ok {
+		opts.Timestamp = time.Now()
+	}
+
+	path, err := AddToNote(strings.Join(title, " "), content, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(path)
+	return nil
+}
+
+var captureCmd = &bonzai.Cmd{
+	Name: "capture",
+	Call: func(cmd *bonzai.Cmd, args ...string) error {
+		dir, err := GetZetDir()
+		if err != nil {
+			return err
+		}
+
+		content, err := readContent(args)
+		if err != nil {
+			return err
+		}
+
+		path, err := AddToNote(GetInbox(dir), content, InsertOptions{Timestamp: time.Now()})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(path)
+		return nil
+	},
+}
+
+// readContent returns text joined by spaces or, when there is none,
+// everything piped to stdin.
+func readContent(text []string) (string, error) {
+	if len(text) > 0 {
+		return strings.Join(text, " "), nil
+	}
+
+	info, err := os.Stdin.Stat()
+	if err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		return "", fmt.Errorf("no content: pipe it to stdin or pass it after --")
+	}
+
+	content, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(string(content)) == "" {
+		return "", fmt.Errorf("no content")
+	}
+	return string(content), nil
+}
+
+// slicesCut splits args around the first sep.
+func slicesCut(args []string, sep string) ([]string, []string, bool) {
+	if i := slices.Index(args, sep); i >= 0 {
+		return args[:i], args[i+1:], true
+	}
+	return args, nil, false
+}
@@ -1,8 +1,12 @@
 package zet
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 )
 
 func GetZetDir() (string, error) {
@@ -13,14 +17,196 @@ func GetZetDir() (string, error) {
 	return dir, nil
 }
 
+// GetEditor returns the editor command line from VISUAL, then EDITOR,
+// defaulting to vi.
 func GetEditor() string {
-	editor := os.Getenv("EDITOR")
-	if editor == "" {
-		return "vi"
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := os.Getenv(name); editor != "" {
+			return editor
+		}
 	}
-	return editor
+	return "vi"
+}
+
+// GetEditorCommand returns the editor command line split into words.
+func GetEditorCommand() ([]string, error) {
+	words, err := SplitCommand(GetEditor())
+	if err != nil {
+		return nil, fmt.Errorf("editor: %w", err)
+	}
+	if len(words) == 0 {
+		return []string{"vi"}, nil
+	}
+	return words, nil
+}
+
+// GetEditorJump returns the argument template used to open a file at
+// a line and column in editor. ZET_EDITOR_JUMP overrides the built-in
+// templates, which cover common terminal and GUI editors. Templates
+// are split into words like a command line before {file}, {line} and
+// {col} are replaced.
+func GetEditorJump(editor string) string {
+	if jump := os.Getenv("ZET_EDITOR_JUMP"); jump != "" {
+		return jump
+	}
+
+	switch filepath.Base(editor) {
+	case "vim", "nvim", "gvim", "mvim":
+		return `"+call cursor({line},{col})" {file}`
+	case "vi", "ex":
+		return "+{line} {file}"
+	case "emacs", "emacsclient", "kak":
+		return "+{line}:{col} {file}"
+	case "nano":
+		return "+{line},{col} {file}"
+	case "code", "code-insiders", "codium", "cursor":
+		return "--goto {file}:{line}:{col}"
+	case "hx", "helix", "micro", "subl", "zed":
+		return "{file}:{line}:{col}"
+	}
+	return "{file}"
 }
 
 func GetRenderer() string {
 	return "glow"
 }
+
+// GetVaultConfigDir returns the directory holding settings shared by
+// everyone using the vault in dir.
+func GetVaultConfigDir(dir string) string {
+	return filepath.Join(dir, ".zet")
+}
+
+func GetHooksDir(dir string) string {
+	return filepath.Join(GetVaultConfigDir(dir), "hooks")
+}
+
+func GetVaultConfigPath(dir string) string {
+	return filepath.Join(GetVaultConfigDir(dir), "config")
+}
+
+// GetOption returns the value of the vault option name. A ZET_<NAME>
+// environment variable, with dashes replaced by underscores, overrides
+// the "name = value" lines of the vault config file.
+func GetOption(dir, name string) string {
+	env := "ZET_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+	if value := os.Getenv(env); value != "" {
+		return value
+	}
+
+	options, err := ReadOptions(dir)
+	if err != nil {
+		return ""
+	}
+	return options[name]
+}
+
+// GetBoolOption reports whether the vault option name is set to a true
+// value such as "true", "yes", "on" or "1".
+func GetBoolOption(dir, name string) bool {
+	switch strings.ToLower(GetOption(dir, name)) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// ReadOptions returns every option in the vault config file.
+func ReadOptions(dir string) (map[string]string, error) {
+	file, err := os.Open(GetVaultConfigPath(dir))
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	options := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		options[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return options, scanner.Err()
+}
+
+// SetOption writes name = value to the vault config file, removing the
+// option when value is empty.
+func SetOption(dir, name, value string) error {
+	options, err := ReadOptions(dir)
+	if err != nil {
+		return err
+	}
+
+	if value == "" {
+		delete(options, name)
+	} else {
+		options[name] = value
+	}
+
+	var names []string
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s = %s\n", name, options[name])
+	}
+
+	err = os.MkdirAll(GetVaultConfigDir(dir), 0755)
+	if err != nil {
+		return err
+	}
+	return AtomicWriteFile(GetVaultConfigPath(dir), []byte(b.String()), 0644)
+}
+
+// GetPostEditSteps returns the steps run after the editor exits, from
+// the comma separated post-edit option. Unset means every step and
+// "none" means no steps.
+func GetPostEditSteps(dir string) []string {
+	option := GetOption(dir, "post-edit")
+	if option == "" {
+		return []string{StepDeleteEmpty, StepRename, StepHeadings, StepIndex, StepHooks}
+	}
+
+	var steps []string
+	for _, step := range strings.Split(option, ",") {
+		step = strings.TrimSpace(step)
+		if step != "" && step != "none" {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+// GetInbox returns the title of the note zet capture adds to, from the
+// inbox option, defaulting to "Inbox".
+func GetInbox(dir string) string {
+	if inbox := GetOption(dir, "inbox"); inbox != "" {
+		return inbox
+	}
+	return "Inbox"
+}
+
+// GetPreviewCommand returns the fzf preview command for note lists, in
+// which {3} is the note path. The preview option selects "text", the
+// default, or "neighbors" to show the notes linked to and from it.
+func GetPreviewCommand(dir string) string {
+	if GetOption(dir, "preview") == "neighbors" {
+		exe, err := os.Executable()
+		if err == nil {
+			return ShellQuote(exe) + " neighbors --file {3}"
+		}
+	}
+	return "cat {3}"
+}
@@ -2,6 +2,7 @@ package zet_test
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/arjungandhi/zet/pkg/zet"
@@ -74,15 +75,22 @@ func TestGetZetDir(t *testing.T) {
 }
 
 func TestGetEditor(t *testing.T) {
-	// Save original env var and restore after test
+	// Save original env vars and restore after test
 	originalEditor := os.Getenv("EDITOR")
+	originalVisual := os.Getenv("VISUAL")
 	defer func() {
 		if originalEditor != "" {
 			os.Setenv("EDITOR", originalEditor)
 		} else {
 			os.Unsetenv("EDITOR")
 		}
+		if originalVisual != "" {
+			os.Setenv("VISUAL", originalVisual)
+		} else {
+			os.Unsetenv("VISUAL")
+		}
 	}()
+	os.Unsetenv("VISUAL")
 
 	tests := []struct {
 		name      string
@@ -131,6 +139,94 @@ func TestGetEditor(t *testing.T) {
 			}
 		})
 	}
+
+	t.Run("VISUAL takes precedence over EDITOR", func(t *testing.T) {
+		os.Setenv("EDITOR", "vim")
+		os.Setenv("VISUAL", "code --wait")
+		defer os.Unsetenv("VISUAL")
+
+		result := zet.GetEditor()
+		if result != "code --wait" {
+			t.Errorf("GetEditor() = %q, want %q", result, "code --wait")
+		}
+	})
+}
+
+func TestEditorArgs(t *testing.T) {
+	originalVisual := os.Getenv("VISUAL")
+	originalJump := os.Getenv("ZET_EDITOR_JUMP")
+	defer func() {
+		if originalVisual != "" {
+			os.Setenv("VISUAL", originalVisual)
+		} else {
+			os.Unsetenv("VISUAL")
+		}
+		if originalJump != "" {
+			os.Setenv("ZET_EDITOR_JUMP", originalJump)
+		} else {
+			os.Unsetenv("ZET_EDITOR_JUMP")
+		}
+	}()
+	os.Unsetenv("ZET_EDITOR_JUMP")
+
+	tests := []struct {
+		name     string
+		editor   string
+		jump     string
+		line     int
+		col      int
+		expected []string
+	}{
+		{
+			name:     "no jump",
+			editor:   "code --wait",
+			expected: []string{"code", "--wait", "/n/My Note.md"},
+		},
+		{
+			name:     "vim line and column",
+			editor:   "nvim -u '/my vimrc'",
+			line:     12,
+			col:      3,
+			expected: []string{"nvim", "-u", "/my vimrc", "+call cursor(12,3)", "/n/My Note.md"},
+		},
+		{
+			name:     "vscode goto",
+			editor:   "/usr/bin/code --wait",
+			line:     4,
+			expected: []string{"/usr/bin/code", "--wait", "--goto", "/n/My Note.md:4:1"},
+		},
+		{
+			name:     "unknown editor ignores jump",
+			editor:   "ed",
+			line:     4,
+			expected: []string{"ed", "/n/My Note.md"},
+		},
+		{
+			name:     "custom template",
+			editor:   "ed",
+			jump:     "-p {line} {file}",
+			line:     4,
+			expected: []string{"ed", "-p", "4", "/n/My Note.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv("VISUAL", tt.editor)
+			if tt.jump != "" {
+				os.Setenv("ZET_EDITOR_JUMP", tt.jump)
+				defer os.Unsetenv("ZET_EDITOR_JUMP")
+			}
+
+			result, err := zet.EditorArgs("/n/My Note.md", tt.line, tt.col)
+			if err != nil {
+				t.Fatalf("EditorArgs() error = %v", err)
+			}
+			if strings.Join(result, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("EditorArgs() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
 }
 
 func TestGetRenderer(t *testing.T) {
@@ -141,3 +237,71 @@ func TestGetRenderer(t *testing.T) {
 		t.Errorf("GetRenderer() = %q, want %q", result, expected)
 	}
 }
+
+func TestOptions(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	if value := zet.GetOption(zetDir, "filenames"); value != "" {
+		t.Errorf("GetOption() without config = %q, want empty", value)
+	}
+
+	err := zet.SetOption(zetDir, "filenames", "ascii")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = zet.SetOption(zetDir, "case-insensitive", "yes")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value := zet.GetOption(zetDir, "filenames"); value != "ascii" {
+		t.Errorf("GetOption() = %q, want %q", value, "ascii")
+	}
+	if !zet.GetBoolOption(zetDir, "case-insensitive") {
+		t.Error("GetBoolOption() = false, want true")
+	}
+
+	originalValue := os.Getenv("ZET_FILENAMES")
+	os.Setenv("ZET_FILENAMES", "unicode")
+	defer func() {
+		if originalValue != "" {
+			os.Setenv("ZET_FILENAMES", originalValue)
+		} else {
+			os.Unsetenv("ZET_FILENAMES")
+		}
+	}()
+
+	if value := zet.GetOption(zetDir, "filenames"); value != "unicode" {
+		t.Errorf("GetOption() with env override = %q, want %q", value, "unicode")
+	}
+
+	err = zet.SetOption(zetDir, "filenames", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	options, err := zet.ReadOptions(zetDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := options["filenames"]; ok {
+		t.Error("SetOption() with empty value should remove the option")
+	}
+}
+
+func TestGetPreviewCommand(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	if preview := zet.GetPreviewCommand(zetDir); preview != "cat {3}" {
+		t.Errorf("GetPreviewCommand() = %q, want %q", preview, "cat {3}")
+	}
+
+	err := zet.SetOption(zetDir, "preview", "neighbors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview := zet.GetPreviewCommand(zetDir); !strings.HasSuffix(preview, "' neighbors --file {3}") {
+		t.Errorf("GetPreviewCommand() = %q, want the neighbors command", preview)
+	}
+}
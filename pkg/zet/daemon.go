@@ -0,0 +1,286 @@
+package zet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var ErrDaemonNotRunning = errors.New("zet daemon is not running")
+
+type daemonRequest struct {
+	Op   string   `json:"op"`
+	Args []string `json:"args,omitempty"`
+}
+
+type daemonResponse struct {
+	Notes []*Note `json:"notes,omitempty"`
+	Error string  `json:"error,omitempty"`
+}
+
+// Daemon keeps the notes of a directory indexed in memory, refreshing
+// the index as files change, and answers queries over a Unix socket.
+type Daemon struct {
+	Dir string
+
+	mu       sync.RWMutex
+	notes    map[string]*Note
+	index    *Index
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// DaemonSocket returns the socket path of the daemon serving dir. It
+// lives outside the vault so that sync tools never see it.
+func DaemonSocket(dir string) string {
+	return filepath.Join(runtimeDir(), "zet-"+vaultKey(dir)+".sock")
+}
+
+// runtimeDir returns the directory for files that only live as long as
+// a zet process, such as the daemon socket.
+func runtimeDir() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return dir
+	}
+	return os.TempDir()
+}
+
+// vaultKey returns a short name for the vault in dir, for files kept
+// about it outside the vault.
+func vaultKey(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return hex.EncodeToString(sum[:6])
+}
+
+func NewDaemon(dir string) *Daemon {
+	return &Daemon{Dir: dir, stop: make(chan struct{})}
+}
+
+// Run loads the notes, starts watching the directory and serves
+// requests until Stop is called or the process is interrupted.
+func (d *Daemon) Run() error {
+	socket := DaemonSocket(d.Dir)
+	if _, err := daemonCall(d.Dir, daemonRequest{Op: "ping"}); err == nil {
+		return fmt.Errorf("zet daemon already running on %s", socket)
+	}
+	os.Remove(socket)
+
+	if err := d.reload(); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(socket)
+
+	watchErr := make(chan error, 1)
+	go func() {
+		watchErr <- Watch(d.Dir, d.stop, d.apply)
+	}()
+
+	// A daemon that can no longer watch the vault would serve a stale
+	// index, so it shuts down and clients go back to scanning
+	failed := make(chan error, 1)
+	interrupted := Interrupted()
+	go func() {
+		select {
+		case <-interrupted:
+		case <-d.stop:
+		case err := <-watchErr:
+			if err != nil {
+				failed <- fmt.Errorf("watching %s: %w", d.Dir, err)
+			}
+		}
+		d.Stop()
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			select {
+			case err := <-failed:
+				return err
+			default:
+			}
+			select {
+			case <-d.stop:
+				return nil
+			default:
+				return err
+			}
+		}
+		go d.serve(conn)
+	}
+}
+
+// Stop shuts the daemon down.
+func (d *Daemon) Stop() {
+	d.stopOnce.Do(func() { close(d.stop) })
+}
+
+func (d *Daemon) reload() error {
+	notes, err := scanNotes(d.Dir)
+	if err != nil {
+		return err
+	}
+
+	byPath := make(map[string]*Note, len(notes))
+	for _, note := range notes {
+		byPath[note.Path] = note
+	}
+
+	d.mu.Lock()
+	d.notes = byPath
+	d.index = BuildIndex(notes)
+	d.mu.Unlock()
+	return nil
+}
+
+func (d *Daemon) apply(events []NoteEvent) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, event := range events {
+		if event.OldPath != "" {
+			delete(d.notes, event.OldPath)
+		}
+		if event.Kind == NoteDeleted {
+			delete(d.notes, event.Path)
+			continue
+		}
+		note, err := ReadNote(event.Path)
+		if err != nil {
+			delete(d.notes, event.Path)
+			continue
+		}
+		d.notes[event.Path] = note
+	}
+
+	notes := make([]*Note, 0, len(d.notes))
+	for _, note := range d.notes {
+		notes = append(notes, note)
+	}
+	SortNotes(notes)
+	d.index = BuildIndex(notes)
+}
+
+// refresh rereads the notes at paths, dropping those that are gone,
+// without waiting for the watcher to notice.
+func (d *Daemon) refresh(paths []string) {
+	var events []NoteEvent
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			events = append(events, NoteEvent{Kind: NoteDeleted, Path: path})
+		} else {
+			events = append(events, NoteEvent{Kind: NoteEdited, Path: path})
+		}
+	}
+	d.apply(events)
+}
+
+func (d *Daemon) serve(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	var req daemonRequest
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		return
+	}
+
+	// Refreshing takes the write lock, which handle cannot
+	if req.Op == "refresh" {
+		d.refresh(req.Args)
+		json.NewEncoder(conn).Encode(daemonResponse{})
+		return
+	}
+
+	resp := d.handle(req)
+	json.NewEncoder(conn).Encode(resp)
+}
+
+func (d *Daemon) handle(req daemonRequest) daemonResponse {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	switch req.Op {
+	case "ping":
+		return daemonResponse{}
+	case "stop":
+		d.Stop()
+		return daemonResponse{}
+	case "list":
+		return daemonResponse{Notes: d.index.Notes}
+	case "search":
+		if len(req.Args) != 1 {
+			return daemonResponse{Error: "search requires a query"}
+		}
+		return daemonResponse{Notes: d.index.Search(req.Args[0])}
+	case "backlinks":
+		if len(req.Args) != 1 {
+			return daemonResponse{Error: "backlinks requires a note path"}
+		}
+		note, ok := d.notes[req.Args[0]]
+		if !ok {
+			return daemonResponse{Error: fmt.Sprintf("unknown note: %s", req.Args[0])}
+		}
+		return daemonResponse{Notes: d.index.Backlinks(note)}
+	default:
+		return daemonResponse{Error: fmt.Sprintf("unknown op: %s", req.Op)}
+	}
+}
+
+// daemonCall sends req to the daemon serving dir, returning
+// ErrDaemonNotRunning if there is none.
+func daemonCall(dir string, req daemonRequest) (*daemonResponse, error) {
+	conn, err := net.DialTimeout("unix", DaemonSocket(dir), 200*time.Millisecond)
+	if err != nil {
+		return nil, ErrDaemonNotRunning
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, err
+	}
+
+	var resp daemonResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return &resp, nil
+}
+
+// DaemonRunning reports whether a daemon is serving dir.
+func DaemonRunning(dir string) bool {
+	_, err := daemonCall(dir, daemonRequest{Op: "ping"})
+	return err == nil
+}
+
+// RefreshDaemon asks the daemon serving dir, if any, to reread the
+// notes at paths.
+func RefreshDaemon(dir string, paths ...string) {
+	daemonCall(dir, daemonRequest{Op: "refresh", Args: paths})
+}
+
+// StopDaemon asks the daemon serving dir to shut down.
+func StopDaemon(dir string) error {
+	_, err := daemonCall(dir, daemonRequest{Op: "stop"})
+	return err
+}
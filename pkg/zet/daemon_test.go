@@ -0,0 +1,117 @@
+package zet_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestDaemon(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	originalZetDir := os.Getenv("ZETDIR")
+	os.Setenv("ZETDIR", zetDir)
+	defer func() {
+		if originalZetDir != "" {
+			os.Setenv("ZETDIR", originalZetDir)
+		} else {
+			os.Unsetenv("ZETDIR")
+		}
+	}()
+
+	err := os.WriteFile(filepath.Join(zetDir, "Apple.md"), []byte("links to [[Banana]]"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if zet.DaemonRunning(zetDir) {
+		t.Fatal("DaemonRunning() = true before start")
+	}
+
+	daemon := zet.NewDaemon(zetDir)
+	done := make(chan error, 1)
+	go func() { done <- daemon.Run() }()
+	defer func() {
+		daemon.Stop()
+		if err := <-done; err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	}()
+
+	waitFor(t, func() bool { return zet.DaemonRunning(zetDir) })
+
+	err = os.WriteFile(filepath.Join(zetDir, "Banana.md"), []byte("yellow fruit"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, func() bool {
+		notes, err := zet.ListNotes()
+		return err == nil && len(notes) == 2
+	})
+
+	notes, err := zet.SearchNotes("yellow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 1 || notes[0].Title != "Banana" {
+		t.Errorf("SearchNotes() = %v, want [Banana]", notes)
+	}
+
+	backlinks, err := zet.NoteBacklinks(notes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backlinks) != 1 || backlinks[0].Title != "Apple" {
+		t.Errorf("NoteBacklinks() = %v, want [Apple]", backlinks)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("timed out waiting for condition")
+}
+
+func TestDaemonStopsWhenWatchFails(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	daemon := zet.NewDaemon(zetDir)
+	done := make(chan error, 1)
+	go func() { done <- daemon.Run() }()
+	waitFor(t, func() bool { return zet.DaemonRunning(zetDir) })
+
+	// Removing the vault leaves nothing to watch
+	err := os.RemoveAll(zetDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Run() error = nil, want the watch failure")
+		}
+	case <-time.After(5 * time.Second):
+		daemon.Stop()
+		t.Fatal("daemon kept running after its watch failed")
+	}
+
+	if zet.DaemonRunning(zetDir) {
+		t.Error("DaemonRunning() = true after the daemon stopped")
+	}
+	if _, err := os.Stat(zet.DaemonSocket(zetDir)); err == nil {
+		t.Error("socket left behind after the daemon stopped")
+	}
+}
@@ -0,0 +1,129 @@
+package zet
+
+import (
+	"fmt"
+	"strings"
+)
+
+type diffOp struct {
+	kind    byte // ' ', '-' or '+'
+	line    string
+	oldLine int // 1-based line in before, for ' ' and '-'
+	newLine int // 1-based line in after, for ' ' and '+'
+}
+
+// UnifiedDiff returns the line differences between before and after in
+// unified diff format with three lines of context, or "" if they are
+// equal. An empty before or after is shown as /dev/null.
+func UnifiedDiff(path, before, after string) string {
+	if before == after {
+		return ""
+	}
+
+	ops := diffLines(splitLines(before), splitLines(after))
+
+	oldName, newName := "a/"+path, "b/"+path
+	if before == "" {
+		oldName = "/dev/null"
+	}
+	if after == "" {
+		newName = "/dev/null"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+
+	const context = 3
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		start := max(i-context, 0)
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j
+			} else if j-end > 2*context {
+				break
+			}
+		}
+		end = min(end+context+1, len(ops))
+
+		oldStart, newStart, oldCount, newCount := 0, 0, 0, 0
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				if oldCount == 0 {
+					oldStart = op.oldLine
+				}
+				oldCount++
+			}
+			if op.kind != '-' {
+				if newCount == 0 {
+					newStart = op.newLine
+				}
+				newCount++
+			}
+		}
+		if oldCount == 0 {
+			oldStart = ops[start].oldLine
+		}
+		if newCount == 0 {
+			newStart = ops[start].newLine
+		}
+
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		for _, op := range ops[start:end] {
+			b.WriteByte(op.kind)
+			b.WriteString(op.line)
+			b.WriteByte('\n')
+		}
+		i = end
+	}
+	return b.String()
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines returns the edit from before to after that keeps their
+// longest common subsequence of lines.
+func diffLines(before, after []string) []diffOp {
+	n, m := len(before), len(after)
+	common := make([][]int, n+1)
+	for i := range common {
+		common[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && before[i] == after[j]:
+			ops = append(ops, diffOp{' ', before[i], i + 1, j + 1})
+			i++
+			j++
+		case i < n && (j == m || common[i+1][j] >= common[i][j+1]):
+			ops = append(ops, diffOp{'-', before[i], i + 1, j})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', after[j], i, j + 1})
+			j++
+		}
+	}
+	return ops
+}
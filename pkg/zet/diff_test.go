@@ -0,0 +1,50 @@
+package zet_test
+
+import (
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected string
+	}{
+		{name: "equal", before: "a\n", after: "a\n", expected: ""},
+		{
+			name:     "change",
+			before:   "1\n2\n3\n4\n5\n",
+			after:    "1\n2\nthree\n4\n5\n",
+			expected: "--- a/n.md\n+++ b/n.md\n@@ -1,5 +1,5 @@\n 1\n 2\n-3\n+three\n 4\n 5\n",
+		},
+		{
+			name:     "separate hunks",
+			before:   "a\n1\n2\n3\n4\n5\n6\n7\n8\nb\n",
+			after:    "A\n1\n2\n3\n4\n5\n6\n7\n8\nB\n",
+			expected: "--- a/n.md\n+++ b/n.md\n@@ -1,4 +1,4 @@\n-a\n+A\n 1\n 2\n 3\n@@ -7,4 +7,4 @@\n 6\n 7\n 8\n-b\n+B\n",
+		},
+		{
+			name:     "deleted",
+			before:   "a\nb\n",
+			after:    "",
+			expected: "--- a/n.md\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name:     "insertion",
+			before:   "a\nb\n",
+			after:    "a\nnew\nb\n",
+			expected: "--- a/n.md\n+++ b/n.md\n@@ -1,2 +1,3 @@\n a\n+new\n b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := zet.UnifiedDiff("n.md", tt.before, tt.after); got != tt.expected {
+				t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, tt.expected)
+			}
+		})
+	}
+}
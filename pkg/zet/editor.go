@@ -0,0 +1,240 @@
+package zet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	bonzai "github.com/rwxrob/bonzai/z"
+)
+
+// EditNote opens the note at path in the editor and reports whether it
+// changed.
+func EditNote(path string) (bool, error) {
+	return EditNoteAt(path, 0, 0)
+}
+
+// EditNoteAt opens the note at path in the editor with the cursor on
+// line and col, when both the editor and line are known, and reports
+// whether it changed. The editor works on a copy while the note is
+// locked; when it exits the copy is written back atomically, unless the
+// note changed underneath in the meantime, as told by its modification
+// time and a hash of its content, in which case the user picks which
+// version to keep. Opening and changing the note are recorded in the
+// usage log.
+//
+// With the editor-mode option set to "exec", zet instead replaces
+// itself with the editor on the note and never returns on success.
+func EditNoteAt(path string, line, col int) (bool, error) {
+	RecordUsage(path, UsageOpened)
+
+	if GetOption(filepath.Dir(path), "editor-mode") == "exec" {
+		args, err := EditorArgs(path, line, col)
+		if err != nil {
+			return false, err
+		}
+		return false, bonzai.SysExec(args...)
+	}
+
+	lock, err := LockNote(path)
+	var locked *LockedError
+	if errors.As(err, &locked) {
+		fmt.Fprintf(os.Stderr, "%v\n", locked)
+		choice, err := Choose("Edit anyway, open read-only, or abort?", "edit", "read-only", "abort")
+		if err != nil {
+			return false, err
+		}
+		switch choice {
+		case 1:
+			return false, viewNote(path, line, col)
+		case 2:
+			return false, fmt.Errorf("aborted")
+		}
+	} else if err != nil {
+		return false, err
+	} else {
+		defer lock.Unlock()
+	}
+
+	before, state, err := readNoteState(path)
+	if err != nil {
+		return false, err
+	}
+
+	work, cleanup, err := workingCopy(path, before, 0644)
+	if err != nil {
+		return false, err
+	}
+	defer cleanup()
+
+	err = runEditor(work, line, col)
+	if err != nil {
+		return false, err
+	}
+
+	after, err := os.ReadFile(work)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(before, after) {
+		return false, nil
+	}
+	RecordUsage(path, UsageEdited)
+
+	if underneath, err := state.changed(path); err != nil || underneath {
+		return true, resolveEditConflict(path, after)
+	}
+	return true, AtomicWriteFile(path, after, 0644)
+}
+
+// noteState is what is compared to tell whether a note changed.
+type noteState struct {
+	modTime time.Time
+	size    int64
+	hash    [sha256.Size]byte
+}
+
+// readNoteState returns the content of the note at path and its state.
+func readNoteState(path string) ([]byte, noteState, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, noteState{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, noteState{}, err
+	}
+	return content, noteState{info.ModTime(), info.Size(), sha256.Sum256(content)}, nil
+}
+
+// changed reports whether the content of the note at path differs from
+// s. It is only hashed again when the modification time or size moved,
+// and a save without edits, which moves only the time, is no change.
+func (s noteState) changed(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	if info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return false, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	return sha256.Sum256(content) != s.hash, nil
+}
+
+// resolveEditConflict asks what to do with edited content when the note
+// at path changed while it was being edited. Unless the user chooses to
+// overwrite or discard, the edits are kept in a conflict copy.
+func resolveEditConflict(path string, edited []byte) error {
+	fmt.Fprintf(os.Stderr, "%s changed while you were editing it\n", filepath.Base(path))
+	choice, err := Choose("Overwrite it, keep both, or discard your edits?", "overwrite", "keep", "discard")
+	if err != nil {
+		choice = 1
+	}
+
+	switch choice {
+	case 0:
+		return AtomicWriteFile(path, edited, 0644)
+	case 2:
+		return nil
+	}
+
+	stem := strings.TrimSuffix(filepath.Base(path), ".md")
+	conflict := filepath.Join(filepath.Dir(path),
+		fmt.Sprintf("%s conflict %s.md", stem, time.Now().Format("20060102 150405")))
+	fmt.Fprintf(os.Stderr, "Saved your edits @ %s\n", conflict)
+	return AtomicWriteFile(conflict, edited, 0644)
+}
+
+// viewNote opens a read-only copy of the note at path in the editor.
+func viewNote(path string, line, col int) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	work, cleanup, err := workingCopy(path, content, 0444)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	return runEditor(work, line, col)
+}
+
+// workingCopy writes content to a file with the same name as path in a
+// fresh temporary directory.
+func workingCopy(path string, content []byte, perm os.FileMode) (string, func(), error) {
+	tmp, err := os.MkdirTemp("", "zet-edit-*")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(tmp) }
+
+	work := filepath.Join(tmp, filepath.Base(path))
+	err = os.WriteFile(work, content, perm)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return work, cleanup, nil
+}
+
+// EditorArgs returns the command line opening path in the editor, at
+// line and col when line is positive.
+func EditorArgs(path string, line, col int) ([]string, error) {
+	editor, err := GetEditorCommand()
+	if err != nil {
+		return nil, err
+	}
+
+	jump := "{file}"
+	if line > 0 {
+		jump = GetEditorJump(editor[0])
+		if col < 1 {
+			col = 1
+		}
+	}
+
+	words, err := SplitCommand(jump)
+	if err != nil {
+		return nil, fmt.Errorf("editor jump template: %w", err)
+	}
+
+	replacer := strings.NewReplacer(
+		"{file}", path,
+		"{line}", strconv.Itoa(line),
+		"{col}", strconv.Itoa(col),
+	)
+	args := append([]string{}, editor...)
+	for _, word := range words {
+		args = append(args, replacer.Replace(word))
+	}
+	return args, nil
+}
+
+// runEditor runs the editor on path as a child process. Interrupts are
+// left to the editor so that zet survives to clean up after it.
+func runEditor(path string, line, col int) error {
+	args, err := EditorArgs(path, line, col)
+	if err != nil {
+		return err
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+
+	return bonzai.Exec(args...)
+}
@@ -0,0 +1,156 @@
+package zet_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+// fakeEditor sets VISUAL to a shell script running script with the file
+// to edit in $1.
+func fakeEditor(t *testing.T, dir, script string) {
+	t.Helper()
+	path := filepath.Join(dir, "editor.sh")
+	err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	originalVisual := os.Getenv("VISUAL")
+	os.Setenv("VISUAL", path)
+	t.Cleanup(func() {
+		if originalVisual != "" {
+			os.Setenv("VISUAL", originalVisual)
+		} else {
+			os.Unsetenv("VISUAL")
+		}
+	})
+}
+
+func TestEditNote(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+	toolDir := ZetDir(t)
+	defer Cleanup(t, toolDir)
+
+	notePath := filepath.Join(zetDir, "Edited.md")
+	err := os.WriteFile(notePath, []byte("before\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lockPath := filepath.Join(zet.GetLocksDir(zetDir), "Edited.md.lock")
+	fakeEditor(t, toolDir, `test -f "`+lockPath+`" && echo after >> "$1"`)
+
+	changed, err := zet.EditNote(notePath)
+	if err != nil {
+		t.Fatalf("EditNote() error = %v", err)
+	}
+	if !changed {
+		t.Error("EditNote() changed = false, want true")
+	}
+
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "before\nafter\n" {
+		t.Errorf("note content = %q, want edits written back", string(content))
+	}
+
+	_, err = os.Stat(lockPath)
+	if err == nil {
+		t.Error("lock should be released after editing")
+	}
+}
+
+func TestEditNoteUnchanged(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+	toolDir := ZetDir(t)
+	defer Cleanup(t, toolDir)
+
+	notePath := filepath.Join(zetDir, "Untouched.md")
+	err := os.WriteFile(notePath, []byte("content"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fakeEditor(t, toolDir, "true")
+
+	changed, err := zet.EditNote(notePath)
+	if err != nil {
+		t.Fatalf("EditNote() error = %v", err)
+	}
+	if changed {
+		t.Error("EditNote() changed = true, want false")
+	}
+}
+
+func TestEditNoteChangedUnderneath(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+	toolDir := ZetDir(t)
+	defer Cleanup(t, toolDir)
+
+	notePath := filepath.Join(zetDir, "Contested.md")
+	err := os.WriteFile(notePath, []byte("original\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another process writes the note while the editor has it open
+	fakeEditor(t, toolDir, `echo theirs > "`+notePath+`"; echo mine > "$1"`)
+
+	changed, err := zet.EditNote(notePath)
+	if err != nil {
+		t.Fatalf("EditNote() error = %v", err)
+	}
+	if !changed {
+		t.Error("EditNote() changed = false, want true")
+	}
+
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "theirs\n" {
+		t.Errorf("note content = %q, want the concurrent change kept", string(content))
+	}
+
+	files, err := zet.ListNoteFiles(zetDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conflict string
+	for _, file := range files {
+		if strings.Contains(filepath.Base(file), "Contested conflict") {
+			conflict = file
+		}
+	}
+	if conflict == "" {
+		t.Fatal("expected a conflict copy holding the edits")
+	}
+
+	content, err = os.ReadFile(conflict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "mine\n" {
+		t.Errorf("conflict copy = %q, want %q", string(content), "mine\n")
+	}
+
+	// A write from outside is not taken for an edit of the user's
+	fakeEditor(t, toolDir, `echo outside > "`+notePath+`"`)
+
+	changed, err = zet.EditNote(notePath)
+	if err != nil {
+		t.Fatalf("EditNote() error = %v", err)
+	}
+	if changed {
+		t.Error("EditNote() after a write from outside changed = true, want false")
+	}
+}
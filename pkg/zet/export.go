@@ -0,0 +1,27 @@
+package zet
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ExportNotes writes each of notes to outDir under its own filename,
+// with embeds and query blocks expanded so that the exported notes
+// stand on their own. all is the full set of notes embeds and queries
+// are resolved against.
+func ExportNotes(all, notes []*Note, outDir string) error {
+	err := os.MkdirAll(outDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	for _, note := range notes {
+		body := ExpandNote(all, note)
+		err = AtomicWriteFile(filepath.Join(outDir, filepath.Base(note.Path)), []byte(body), 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package zet_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestExportNotes(t *testing.T) {
+	outDir := ZetDir(t)
+	defer Cleanup(t, outDir)
+
+	notes := []*zet.Note{
+		{Title: "Main", Path: "/vault/Main.md", Body: "# Main\n![[Part]]\n"},
+		{Title: "Part", Path: "/vault/Part.md", Body: "# Part\n\npart text\n"},
+	}
+
+	err := zet.ExportNotes(notes, notes[:1], filepath.Join(outDir, "site"))
+	if err != nil {
+		t.Fatalf("ExportNotes() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outDir, "site", "Main.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "# Main\npart text\n" {
+		t.Errorf("exported content = %q, want embeds expanded", string(content))
+	}
+
+	_, err = os.Stat(filepath.Join(outDir, "site", "Part.md"))
+	if err == nil {
+		t.Error("ExportNotes() should only export the given notes")
+	}
+}
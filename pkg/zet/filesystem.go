@@ -1,25 +1,138 @@
 package zet
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
 )
 
+var ErrEmptyFilename = errors.New("title has no letters or digits to use as a filename")
+
+// SanitizeFilename NFC-normalizes title and keeps only letters, digits,
+// combining marks and single spaces, from any script.
 func SanitizeFilename(title string) string {
-	re := regexp.MustCompile(`[^a-zA-Z0-9 ]`)
-	sanitized := re.ReplaceAllString(title, "")
-	sanitized = strings.Join(strings.Fields(sanitized), " ")
-	return strings.TrimSpace(sanitized)
+	title = norm.NFC.String(title)
+	sanitized := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) {
+			return r
+		}
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		return -1
+	}, title)
+	return strings.Join(strings.Fields(sanitized), " ")
+}
+
+// TransliterateFilename sanitizes title down to ASCII letters, digits
+// and spaces, transliterating accented Latin, Greek and Cyrillic
+// letters and dropping everything else.
+func TransliterateFilename(title string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(title) {
+		switch {
+		case r <= unicode.MaxASCII:
+			b.WriteRune(r)
+		case unicode.Is(unicode.Mn, r):
+		case transliterations[unicode.ToLower(r)] != "":
+			ascii := transliterations[unicode.ToLower(r)]
+			if unicode.IsUpper(r) {
+				ascii = strings.ToUpper(ascii[:1]) + ascii[1:]
+			}
+			b.WriteString(ascii)
+		case unicode.IsSpace(r):
+			b.WriteRune(' ')
+		}
+	}
+
+	ascii := strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII {
+			return -1
+		}
+		return r
+	}, SanitizeFilename(b.String()))
+	return strings.Join(strings.Fields(ascii), " ")
+}
+
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'ł': "l", 'đ': "d", 'ð': "d", 'þ': "th", 'ı': "i",
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh",
+	'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "iu", 'я': "ia", 'і': "i", 'ї': "i",
+	'є': "ie", 'ґ': "g",
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th", 'ι': "i",
+	'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s",
+	'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// NoteFilename returns the filename, without directory, that title is
+// stored under in dir. The vault option "filenames" selects between
+// "unicode" names (the default) and transliterated "ascii" names.
+func NoteFilename(dir, title string) (string, error) {
+	var sanitized string
+	if GetOption(dir, "filenames") == "ascii" {
+		sanitized = TransliterateFilename(title)
+	} else {
+		sanitized = SanitizeFilename(title)
+	}
+
+	if sanitized == "" {
+		return "", fmt.Errorf("%w: %q", ErrEmptyFilename, title)
+	}
+	return sanitized + ".md", nil
 }
 
 func NoteExists(dir, title string) bool {
-	sanitized := SanitizeFilename(title)
-	path := filepath.Join(dir, sanitized+".md")
-	_, err := os.Stat(path)
-	return err == nil
+	filename, err := NoteFilename(dir, title)
+	if err != nil {
+		return false
+	}
+	_, ok := FindNoteFile(dir, filename)
+	return ok
+}
+
+// FindNoteFile returns the path of filename in dir. When the vault
+// option "case-insensitive" is set, a file whose name differs only in
+// case also counts, as it would on a case-insensitive sync target.
+func FindNoteFile(dir, filename string) (string, bool) {
+	path := filepath.Join(dir, filename)
+	if _, err := os.Stat(path); err == nil {
+		return path, true
+	}
+
+	if !GetBoolOption(dir, "case-insensitive") {
+		return "", false
+	}
+
+	files, err := ListNoteFiles(dir)
+	if err != nil {
+		return "", false
+	}
+	for _, file := range files {
+		if strings.EqualFold(filepath.Base(file), filename) {
+			return file, true
+		}
+	}
+	return "", false
+}
+
+// DisambiguateFilename returns the first of "name 2.md", "name 3.md"
+// and so on that does not exist in dir.
+func DisambiguateFilename(dir, filename string) string {
+	stem := strings.TrimSuffix(filename, ".md")
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s %d.md", stem, i)
+		if _, ok := FindNoteFile(dir, candidate); !ok {
+			return candidate
+		}
+	}
 }
 
 func ReadNote(path string) (*Note, error) {
@@ -28,8 +141,10 @@ func ReadNote(path string) (*Note, error) {
 		return nil, err
 	}
 
-	filename := filepath.Base(path)
-	title := strings.TrimSuffix(filename, ".md")
+	title := ParseTitle(string(content))
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(path), ".md")
+	}
 
 	return &Note{
 		Title: title,
@@ -38,10 +153,74 @@ func ReadNote(path string) (*Note, error) {
 	}, nil
 }
 
+// ParseTitle returns the text of the H1 heading on the first non-blank
+// line of body, after any frontmatter, or "" if there is none.
+func ParseTitle(body string) string {
+	for _, line := range strings.Split(StripFrontmatter(body), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(line[2:])
+		}
+		return ""
+	}
+	return ""
+}
+
 func WriteNote(dir, title, content string) error {
-	sanitized := SanitizeFilename(title)
-	path := filepath.Join(dir, sanitized+".md")
-	return os.WriteFile(path, []byte(content), 0644)
+	filename, err := NoteFilename(dir, title)
+	if err != nil {
+		return err
+	}
+	return AtomicWriteFile(filepath.Join(dir, filename), []byte(content), 0644)
+}
+
+// AtomicWriteFile writes data to a temporary file next to path, syncs
+// it and renames it over path, so that a crash leaves either the old or
+// the new content but never a truncated file. An existing file keeps
+// its mode, perm being used for new files, and a symlink is written
+// through to its target.
+func AtomicWriteFile(path string, data []byte, perm os.FileMode) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), perm)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return err
+	}
+
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
 }
 
 func ListNoteFiles(dir string) ([]string, error) {
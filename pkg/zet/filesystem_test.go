@@ -1,6 +1,7 @@
 package zet_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -54,6 +55,26 @@ func TestSanitizeFilename(t *testing.T) {
 			input:    "!@#$%^&*()",
 			expected: "",
 		},
+		{
+			name:     "accented latin",
+			input:    "Über Café",
+			expected: "Über Café",
+		},
+		{
+			name:     "decomposed accents are normalized",
+			input:    "Cafe\u0301",
+			expected: "Café",
+		},
+		{
+			name:     "japanese",
+			input:    "日本語のメモ!",
+			expected: "日本語のメモ",
+		},
+		{
+			name:     "cyrillic",
+			input:    "Привет, мир",
+			expected: "Привет мир",
+		},
 	}
 
 	for _, tt := range tests {
@@ -66,6 +87,161 @@ func TestSanitizeFilename(t *testing.T) {
 	}
 }
 
+func TestTransliterateFilename(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "ascii", input: "What's the plan?", expected: "Whats the plan"},
+		{name: "accented latin", input: "Über Café", expected: "Uber Cafe"},
+		{name: "special latin", input: "Straße Ærø", expected: "Strasse Aero"},
+		{name: "cyrillic", input: "Привет мир", expected: "Privet mir"},
+		{name: "greek", input: "Λόγος", expected: "Logos"},
+		{name: "untransliterable", input: "日本語", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := zet.TransliterateFilename(tt.input)
+			if result != tt.expected {
+				t.Errorf("TransliterateFilename(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNoteFilename(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	filename, err := zet.NoteFilename(zetDir, "Über Café")
+	if err != nil || filename != "Über Café.md" {
+		t.Errorf("NoteFilename() = %q, %v, want %q", filename, err, "Über Café.md")
+	}
+
+	_, err = zet.NoteFilename(zetDir, "?!")
+	if !errors.Is(err, zet.ErrEmptyFilename) {
+		t.Errorf("NoteFilename() error = %v, want ErrEmptyFilename", err)
+	}
+
+	err = zet.SetOption(zetDir, "filenames", "ascii")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename, err = zet.NoteFilename(zetDir, "Über Café")
+	if err != nil || filename != "Uber Cafe.md" {
+		t.Errorf("NoteFilename() in ascii mode = %q, %v, want %q", filename, err, "Uber Cafe.md")
+	}
+}
+
+func TestAtomicWriteFile(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	path := filepath.Join(zetDir, "Atomic.md")
+	err := os.WriteFile(path, []byte("old content"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = zet.AtomicWriteFile(path, []byte("new"), 0600)
+	if err != nil {
+		t.Fatalf("AtomicWriteFile() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "new" {
+		t.Errorf("content = %q, want %q", string(content), "new")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("mode = %v, want the existing 0644 kept", info.Mode().Perm())
+	}
+
+	entries, err := os.ReadDir(zetDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("AtomicWriteFile() left %d files behind, want 1", len(entries))
+	}
+
+	created := filepath.Join(zetDir, "Created.md")
+	err = zet.AtomicWriteFile(created, []byte("new"), 0600)
+	if err != nil {
+		t.Fatalf("AtomicWriteFile() error = %v", err)
+	}
+	info, err = os.Stat(created)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode of a new file = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestAtomicWriteFileSymlink(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+	otherDir := ZetDir(t)
+	defer Cleanup(t, otherDir)
+
+	target := filepath.Join(otherDir, "Target.md")
+	err := os.WriteFile(target, []byte("old"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(zetDir, "Link.md")
+	err = os.Symlink(target, link)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = zet.AtomicWriteFile(link, []byte("new"), 0644)
+	if err != nil {
+		t.Fatalf("AtomicWriteFile() error = %v", err)
+	}
+
+	info, err := os.Lstat(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Error("AtomicWriteFile() replaced the symlink with a regular file")
+	}
+	content, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "new" {
+		t.Errorf("symlink target content = %q, want %q", content, "new")
+	}
+}
+
+func TestWriteNoteEmptyFilename(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	err := zet.WriteNote(zetDir, "???", "content")
+	if !errors.Is(err, zet.ErrEmptyFilename) {
+		t.Errorf("WriteNote() error = %v, want ErrEmptyFilename", err)
+	}
+
+	_, err = os.Stat(filepath.Join(zetDir, ".md"))
+	if err == nil {
+		t.Error("WriteNote() should not create .md")
+	}
+}
+
 func TestNoteExists(t *testing.T) {
 	zetDir := ZetDir(t)
 	defer Cleanup(t, zetDir)
@@ -184,6 +360,54 @@ func TestReadNote(t *testing.T) {
 	}
 }
 
+func TestReadNoteTitleFromHeading(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	path := filepath.Join(zetDir, "Whats the plan.md")
+	err := os.WriteFile(path, []byte("# What's the plan?\n\nbody"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	note, err := zet.ReadNote(path)
+	if err != nil {
+		t.Fatalf("ReadNote() error = %v", err)
+	}
+
+	if note.Title != "What's the plan?" {
+		t.Errorf("note.Title = %q, want %q", note.Title, "What's the plan?")
+	}
+
+	if note.Name() != "Whats the plan" {
+		t.Errorf("note.Name() = %q, want %q", note.Name(), "Whats the plan")
+	}
+}
+
+func TestParseTitle(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{name: "leading heading", body: "# C++ Notes\n\nbody", expected: "C++ Notes"},
+		{name: "blank lines before heading", body: "\n\n# Title\n", expected: "Title"},
+		{name: "after frontmatter", body: "---\ntags: [a]\n---\n# Title\n", expected: "Title"},
+		{name: "heading after text", body: "intro\n# Title", expected: ""},
+		{name: "second level heading", body: "## Section", expected: ""},
+		{name: "empty", body: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := zet.ParseTitle(tt.body)
+			if result != tt.expected {
+				t.Errorf("ParseTitle(%q) = %q, want %q", tt.body, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestListNoteFiles(t *testing.T) {
 	zetDir := ZetDir(t)
 	defer Cleanup(t, zetDir)
@@ -8,20 +8,97 @@ import (
 	"strings"
 )
 
+// FindNote lets the user pick one of notes with fzf, listing pinned
+// notes first and then the most frecently used. Once a query is typed
+// fzf ranks the matches, and that order only breaks ties between
+// equally good ones.
 func FindNote(notes []*Note, searchTerm string) (*Note, error) {
 	if len(notes) == 0 {
 		return nil, fmt.Errorf("no notes to search")
 	}
 
-	input := BuildFzfInput(notes)
+	dir, _ := GetZetDir()
+	notes, pinned := PinFirst(dir, RankByFrecency(dir, notes))
 
+	// The pin marker is shown before the title but not searched
+	output, err := runFzf(BuildFzfInputPinned(notes, pinned), searchTerm, GetPreviewCommand(dir),
+		"--tiebreak=index", "--with-nth=4,2", "--nth=2", "--tabstop=1")
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseFzfOutput(notes, output)
+}
+
+// FindNoteExact returns the note whose title or filename is name,
+// without asking the user.
+func FindNoteExact(notes []*Note, name string) (*Note, error) {
+	note := LookupNote(notes, name)
+	if note == nil {
+		return nil, fmt.Errorf("no note named %q", name)
+	}
+	return note, nil
+}
+
+// FindFirstNote returns the best fzf match for searchTerm without
+// asking the user.
+func FindFirstNote(notes []*Note, searchTerm string) (*Note, error) {
+	if len(notes) == 0 {
+		return nil, fmt.Errorf("no notes to search")
+	}
+
+	dir, _ := GetZetDir()
+	notes = RankByFrecency(dir, notes)
+
+	cmd := exec.Command("fzf", "--delimiter=\t", "--nth=2", "--tiebreak=index", "--filter="+searchTerm)
+	cmd.Stdin = strings.NewReader(BuildFzfInput(notes))
+	cmd.Stderr = os.Stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("no note matches %q", searchTerm)
+	}
+
+	first, _, _ := strings.Cut(string(output), "\n")
+	return ParseFzfOutput(notes, first)
+}
+
+// FindSearchHit lets the user pick one of hits with fzf.
+func FindSearchHit(hits []SearchHit, searchTerm string) (*SearchHit, error) {
+	if len(hits) == 0 {
+		return nil, fmt.Errorf("no matches")
+	}
+
+	var lines []string
+	for i, hit := range hits {
+		text := strings.TrimSpace(hit.Text)
+		lines = append(lines, fmt.Sprintf("%d\t%s:%d: %s\t%s", i, hit.Note.Title, hit.Line, text, hit.Note.Path))
+	}
+
+	output, err := runFzf(strings.Join(lines, "\n"), searchTerm, "cat {3}")
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := parseFzfIndex(output, len(hits))
+	if err != nil {
+		return nil, err
+	}
+	return &hits[index], nil
+}
+
+// runFzf runs fzf over tab separated input lines of index, display text
+// and path, showing preview for the selected line, and returns it.
+// extra options are passed after the defaults, which they override.
+func runFzf(input, searchTerm, preview string, extra ...string) (string, error) {
 	args := []string{
 		"--delimiter=\t",
 		"--with-nth=2",
 		"--layout=reverse",
 		"-1",
-		"--preview=cat {3}",
+		"--preview=" + preview,
 	}
+	args = append(args, extra...)
 
 	if searchTerm != "" {
 		args = append([]string{fmt.Sprintf("--query=%s", searchTerm)}, args...)
@@ -33,40 +110,71 @@ func FindNote(notes []*Note, searchTerm string) (*Note, error) {
 
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-
-	return ParseFzfOutput(notes, string(output))
+	return string(output), nil
 }
 
+// BuildFzfInput returns a line for each of notes, followed by a line
+// for each of its aliases, all carrying the index of the note.
 func BuildFzfInput(notes []*Note) string {
+	return buildFzfInput(notes, func(int) string { return "" })
+}
+
+// BuildFzfInputPinned is BuildFzfInput with a fourth field on every
+// line, holding PinMarker for the first pinned of notes, so that the
+// marker can be shown with --with-nth=4,2 while only the title is
+// searched with --nth=2.
+func BuildFzfInputPinned(notes []*Note, pinned int) string {
+	return buildFzfInput(notes, func(i int) string {
+		if i < pinned {
+			return "\t" + PinMarker + "\t"
+		}
+		return "\t\t"
+	})
+}
+
+func buildFzfInput(notes []*Note, suffix func(int) string) string {
 	var lines []string
 	for i, note := range notes {
-		line := fmt.Sprintf("%d\t%s\t%s", i, note.Title, note.Path)
+		line := fmt.Sprintf("%d\t%s\t%s%s", i, note.Title, note.Path, suffix(i))
 		lines = append(lines, line)
+		for _, alias := range NoteAliases(note) {
+			line := fmt.Sprintf("%d\t%s → %s\t%s%s", i, alias, note.Title, note.Path, suffix(i))
+			lines = append(lines, line)
+		}
 	}
 	return strings.Join(lines, "\n")
 }
 
 func ParseFzfOutput(notes []*Note, output string) (*Note, error) {
+	index, err := parseFzfIndex(output, len(notes))
+	if err != nil {
+		return nil, err
+	}
+
+	return notes[index], nil
+}
+
+func parseFzfIndex(output string, count int) (int, error) {
 	output = strings.TrimSpace(output)
 	if output == "" {
-		return nil, fmt.Errorf("empty fzf output")
+		return 0, fmt.Errorf("empty fzf output")
 	}
 
 	parts := strings.Split(output, "\t")
 	if len(parts) < 1 {
-		return nil, fmt.Errorf("malformed fzf output")
+		return 0, fmt.Errorf("malformed fzf output")
 	}
 
 	index, err := strconv.Atoi(parts[0])
 	if err != nil {
-		return nil, fmt.Errorf("invalid index in fzf output: %w", err)
+		return 0, fmt.Errorf("invalid index in fzf output: %w", err)
 	}
 
-	if index < 0 || index >= len(notes) {
-		return nil, fmt.Errorf("index out of range: %d", index)
+	if index < 0 || index >= count {
+		return 0, fmt.Errorf("index out of range: %d", index)
 	}
 
-	return notes[index], nil
+	return index, nil
 }
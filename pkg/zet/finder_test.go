@@ -112,3 +112,57 @@ func TestParseFzfOutput(t *testing.T) {
 		})
 	}
 }
+
+func TestFindNoteExact(t *testing.T) {
+	notes := []*zet.Note{
+		{Title: "What's the plan?", Path: "/tmp/Whats the plan.md"},
+	}
+
+	for _, name := range []string{"What's the plan?", "Whats the plan"} {
+		note, err := zet.FindNoteExact(notes, name)
+		if err != nil || note != notes[0] {
+			t.Errorf("FindNoteExact(%q) = %v, %v, want %v", name, note, err, notes[0])
+		}
+	}
+
+	_, err := zet.FindNoteExact(notes, "plan")
+	if err == nil {
+		t.Error("FindNoteExact() of a partial title should fail")
+	}
+}
+
+func TestBuildFzfInputAliases(t *testing.T) {
+	notes := []*zet.Note{
+		{Title: "Command Line", Path: "/tmp/Command Line.md", Body: "---\naliases: [CLI]\n---\n"},
+		{Title: "Other", Path: "/tmp/Other.md"},
+	}
+
+	input := zet.BuildFzfInput(notes)
+
+	expected := "0\tCommand Line\t/tmp/Command Line.md\n" +
+		"0\tCLI → Command Line\t/tmp/Command Line.md\n" +
+		"1\tOther\t/tmp/Other.md"
+	if input != expected {
+		t.Errorf("BuildFzfInput() = %q, want %q", input, expected)
+	}
+
+	note, err := zet.ParseFzfOutput(notes, "0\tCLI → Command Line\t/tmp/Command Line.md\n")
+	if err != nil || note != notes[0] {
+		t.Errorf("ParseFzfOutput() of an alias row = %v, %v, want Command Line", note, err)
+	}
+}
+
+func TestBuildFzfInputPinned(t *testing.T) {
+	notes := []*zet.Note{
+		{Title: "Start Here", Path: "/tmp/Start Here.md"},
+		{Title: "Other", Path: "/tmp/Other.md"},
+	}
+
+	input := zet.BuildFzfInputPinned(notes, 1)
+
+	expected := "0\tStart Here\t/tmp/Start Here.md\t📌\t\n" +
+		"1\tOther\t/tmp/Other.md\t\t"
+	if input != expected {
+		t.Errorf("BuildFzfInputPinned() = %q, want %q", input, expected)
+	}
+}
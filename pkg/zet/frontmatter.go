@@ -0,0 +1,225 @@
+package zet
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SplitFrontmatter splits body into its leading "---" delimited
+// frontmatter block, delimiters included, and the rest. The block is
+// empty if body has no frontmatter.
+func SplitFrontmatter(body string) (string, string) {
+	if !strings.HasPrefix(body, "---\n") && !strings.HasPrefix(body, "---\r\n") {
+		return "", body
+	}
+
+	offset := strings.Index(body, "\n") + 1
+	for offset < len(body) {
+		end := strings.Index(body[offset:], "\n")
+		line := body[offset:]
+		if end >= 0 {
+			line = body[offset : offset+end]
+		}
+		if strings.TrimSpace(line) == "---" {
+			if end < 0 {
+				return body, ""
+			}
+			return body[:offset+end+1], body[offset+end+1:]
+		}
+		if end < 0 {
+			break
+		}
+		offset += end + 1
+	}
+	return "", body
+}
+
+// StripFrontmatter returns body without its frontmatter block.
+func StripFrontmatter(body string) string {
+	_, rest := SplitFrontmatter(body)
+	return strings.TrimLeft(rest, "\r\n")
+}
+
+type Field struct {
+	Key    string
+	Values []string
+	List   bool // Written as a list rather than a single value
+}
+
+var aliasesLineRe = regexp.MustCompile(`(?i)^alias(?:es)?:\s*(.*)$`)
+
+// NoteAliases returns the alternative names of note, declared in the
+// aliases or alias frontmatter field or on an "aliases: a, b" line at
+// the start of the body, next to the title.
+func NoteAliases(note *Note) []string {
+	seen := make(map[string]bool)
+	var aliases []string
+	add := func(alias string) {
+		alias = unquote(strings.TrimSpace(alias))
+		if alias != "" && !seen[alias] {
+			seen[alias] = true
+			aliases = append(aliases, alias)
+		}
+	}
+
+	fm := ParseFrontmatter(note.Body)
+	for _, key := range []string{"aliases", "alias"} {
+		for _, alias := range fm.Get(key) {
+			add(alias)
+		}
+	}
+
+	_, rest := SplitFrontmatter(note.Body)
+	for _, line := range leadingLines(CodeMaskedLines(rest)) {
+		m := aliasesLineRe.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		list := strings.TrimSuffix(strings.TrimPrefix(m[1], "["), "]")
+		for _, alias := range strings.Split(list, ",") {
+			add(alias)
+		}
+	}
+	return aliases
+}
+
+// leadingLines returns the metadata block at the start of a body: the
+// first paragraph, or the one after the title if the body starts with
+// one. A line like "Alias: ..." later in the prose is not metadata.
+func leadingLines(lines []string) []string {
+	start := 0
+	skipBlank := func() {
+		for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+			start++
+		}
+	}
+	skipBlank()
+	if start < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[start]), "# ") {
+		start++
+		skipBlank()
+	}
+	end := start
+	for end < len(lines) && strings.TrimSpace(lines[end]) != "" {
+		end++
+	}
+	return lines[start:end]
+}
+
+// Frontmatter holds the fields of a frontmatter block in order. Only the
+// subset of YAML notes commonly use is understood: "key: value",
+// "key: [a, b]" and "key:" followed by "- item" lines.
+type Frontmatter []Field
+
+func ParseFrontmatter(body string) Frontmatter {
+	block, _ := SplitFrontmatter(body)
+	if block == "" {
+		return nil
+	}
+
+	lines := strings.Split(strings.TrimRight(block, "\r\n"), "\n")
+	lines = lines[1 : len(lines)-1]
+
+	var fm Frontmatter
+	for _, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "- ") && len(fm) > 0 && line != trimmed {
+			last := &fm[len(fm)-1]
+			last.List = true
+			last.Values = append(last.Values, unquote(strings.TrimSpace(trimmed[2:])))
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		field := Field{Key: strings.TrimSpace(key)}
+		value = strings.TrimSpace(value)
+		switch {
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			field.List = true
+			for _, item := range strings.Split(value[1:len(value)-1], ",") {
+				if item = unquote(strings.TrimSpace(item)); item != "" {
+					field.Values = append(field.Values, item)
+				}
+			}
+		case value != "":
+			field.Values = []string{unquote(value)}
+		}
+		fm = append(fm, field)
+	}
+	return fm
+}
+
+// Get returns the values of the field key, matched case-insensitively.
+func (fm Frontmatter) Get(key string) []string {
+	field, _ := fm.Field(key)
+	return field.Values
+}
+
+// Field returns the field key, matched case-insensitively.
+func (fm Frontmatter) Field(key string) (Field, bool) {
+	for _, field := range fm {
+		if strings.EqualFold(field.Key, key) {
+			return field, true
+		}
+	}
+	return Field{}, false
+}
+
+// Set returns fm with the field key replaced by, or appended as, field.
+func (fm Frontmatter) Set(field Field) Frontmatter {
+	result := append(Frontmatter{}, fm...)
+	for i := range result {
+		if strings.EqualFold(result[i].Key, field.Key) {
+			result[i] = field
+			return result
+		}
+	}
+	return append(result, field)
+}
+
+// String returns fm as a frontmatter block, or "" if it has no fields.
+func (fm Frontmatter) String() string {
+	if len(fm) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	for _, field := range fm {
+		switch {
+		case field.List:
+			var items []string
+			for _, value := range field.Values {
+				items = append(items, quote(value))
+			}
+			b.WriteString(field.Key + ": [" + strings.Join(items, ", ") + "]\n")
+		case len(field.Values) > 0:
+			b.WriteString(field.Key + ": " + quote(field.Values[0]) + "\n")
+		default:
+			b.WriteString(field.Key + ":\n")
+		}
+	}
+	b.WriteString("---\n")
+	return b.String()
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+func quote(value string) string {
+	if strings.ContainsAny(value, ":,[]#\"'") || strings.TrimSpace(value) != value {
+		return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+	}
+	return value
+}
@@ -0,0 +1,131 @@
+package zet_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestSplitFrontmatter(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		expectedFront string
+		expectedRest  string
+	}{
+		{
+			name:          "frontmatter",
+			body:          "---\ntags: [a]\n---\n# Title\n",
+			expectedFront: "---\ntags: [a]\n---\n",
+			expectedRest:  "# Title\n",
+		},
+		{
+			name:          "no frontmatter",
+			body:          "# Title\n---\n",
+			expectedFront: "",
+			expectedRest:  "# Title\n---\n",
+		},
+		{
+			name:          "unterminated frontmatter",
+			body:          "---\ntags: [a]\n",
+			expectedFront: "",
+			expectedRest:  "---\ntags: [a]\n",
+		},
+		{
+			name:          "frontmatter only",
+			body:          "---\ntags: [a]\n---",
+			expectedFront: "---\ntags: [a]\n---",
+			expectedRest:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			front, rest := zet.SplitFrontmatter(tt.body)
+			if front != tt.expectedFront || rest != tt.expectedRest {
+				t.Errorf("SplitFrontmatter() = %q, %q, want %q, %q", front, rest, tt.expectedFront, tt.expectedRest)
+			}
+		})
+	}
+}
+
+func TestStripFrontmatter(t *testing.T) {
+	result := zet.StripFrontmatter("---\ntags: [a]\n---\n\n# Title\n")
+	if result != "# Title\n" {
+		t.Errorf("StripFrontmatter() = %q, want %q", result, "# Title\n")
+	}
+}
+
+func TestParseFrontmatter(t *testing.T) {
+	body := "---\ntitle: \"Q: A\"\ntags: [a, 'b c']\naliases:\n  - One\n  - Two\nempty:\n---\n# Title\n"
+
+	fm := zet.ParseFrontmatter(body)
+
+	tests := []struct {
+		key      string
+		expected []string
+	}{
+		{"title", []string{"Q: A"}},
+		{"Tags", []string{"a", "b c"}},
+		{"aliases", []string{"One", "Two"}},
+		{"empty", nil},
+		{"missing", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got := fm.Get(tt.key)
+			if strings.Join(got, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("Get(%q) = %q, want %q", tt.key, got, tt.expected)
+			}
+		})
+	}
+
+	if zet.ParseFrontmatter("# Title\n") != nil {
+		t.Error("ParseFrontmatter() without frontmatter should be nil")
+	}
+}
+
+func TestFrontmatterString(t *testing.T) {
+	fm := zet.ParseFrontmatter("---\ntitle: Note\ntags:\n  - a\n---\n")
+	fm = fm.Set(zet.Field{Key: "tags", Values: []string{"a", "b"}, List: true})
+	fm = fm.Set(zet.Field{Key: "source", Values: []string{"x: y"}})
+
+	expected := "---\ntitle: Note\ntags: [a, b]\nsource: \"x: y\"\n---\n"
+	if got := fm.String(); got != expected {
+		t.Errorf("String() = %q, want %q", got, expected)
+	}
+
+	reparsed := zet.ParseFrontmatter(fm.String())
+	if got := reparsed.Get("source"); len(got) != 1 || got[0] != "x: y" {
+		t.Errorf("reparsed source = %q, want [\"x: y\"]", got)
+	}
+}
+
+func TestNoteAliases(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{name: "frontmatter list", body: "---\naliases:\n  - CLI\n  - Shell\n---\n# Command Line\n", expected: []string{"CLI", "Shell"}},
+		{name: "frontmatter alias", body: "---\nalias: CLI\n---\n", expected: []string{"CLI"}},
+		{name: "aliases line", body: "# Command Line\naliases: CLI, 'Shell'\n", expected: []string{"CLI", "Shell"}},
+		{name: "bracketed line", body: "# Command Line\nAliases: [CLI, Shell]\n", expected: []string{"CLI", "Shell"}},
+		{name: "deduplicated", body: "---\naliases: [CLI]\n---\naliases: CLI\n", expected: []string{"CLI"}},
+		{name: "after a blank line", body: "# Command Line\n\naliases: CLI\n", expected: []string{"CLI"}},
+		{name: "next to tags", body: "# Command Line\n#tools\nalias: CLI\n", expected: []string{"CLI"}},
+		{name: "prose is skipped", body: "# Command Line\naliases: CLI\n\nSome text.\n\nAlias: Smith, the author\n", expected: []string{"CLI"}},
+		{name: "code is skipped", body: "```\naliases: CLI\n```\n", expected: nil},
+		{name: "none", body: "# Command Line\n", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := zet.NoteAliases(&zet.Note{Body: tt.body})
+			if strings.Join(got, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("NoteAliases() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
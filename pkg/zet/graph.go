@@ -0,0 +1,400 @@
+package zet
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+)
+
+// Graph is the link graph of a set of notes. Edges refer to notes by
+// their index in Nodes and run from the linking note to the linked one.
+type Graph struct {
+	Nodes []*Note
+	Edges []Edge
+}
+
+type Edge struct {
+	From int
+	To   int
+}
+
+type GraphFormat string
+
+const (
+	FormatDOT     GraphFormat = "dot"
+	FormatGraphML GraphFormat = "graphml"
+	FormatJSON    GraphFormat = "json"
+	FormatCanvas  GraphFormat = "canvas"
+)
+
+// tagColors is the palette nodes are colored from by their first tag.
+var tagColors = []string{
+	"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f",
+	"#edc948", "#b07aa1", "#ff9da7", "#9c755f", "#bab0ac",
+}
+
+// BuildGraph returns the graph of the links between notes. Links to
+// notes outside notes, self links and repeated links are left out.
+func BuildGraph(notes []*Note) *Graph {
+	g := &Graph{Nodes: notes}
+
+	index := make(map[*Note]int, len(notes))
+	for i, note := range notes {
+		index[note] = i
+	}
+
+	r := newResolver(notes)
+	for i, note := range notes {
+		seen := make(map[int]bool)
+		for _, link := range ParseLinks(note.Body) {
+			target := r.lookup(link.Target)
+			if target == nil {
+				continue
+			}
+			j := index[target]
+			if j == i || seen[j] {
+				continue
+			}
+			seen[j] = true
+			g.Edges = append(g.Edges, Edge{From: i, To: j})
+		}
+	}
+
+	return g
+}
+
+// Filter returns the subgraph of the notes for which keep returns true.
+func (g *Graph) Filter(keep func(*Note) bool) *Graph {
+	kept := make(map[int]bool)
+	for i, note := range g.Nodes {
+		if keep(note) {
+			kept[i] = true
+		}
+	}
+	return g.subgraph(kept)
+}
+
+// Around returns the subgraph of the notes within depth links of note,
+// following links in either direction.
+func (g *Graph) Around(note *Note, depth int) *Graph {
+	start := g.indexOf(note)
+	if start < 0 {
+		return &Graph{}
+	}
+
+	distances := g.Distances(start, false)
+	kept := make(map[int]bool)
+	for i, d := range distances {
+		if d <= depth {
+			kept[i] = true
+		}
+	}
+	return g.subgraph(kept)
+}
+
+// Distances returns the number of links between the node at start and
+// every node it reaches, following links only forwards when directed.
+func (g *Graph) Distances(start int, directed bool) map[int]int {
+	adjacent := g.adjacency(directed)
+	distances := map[int]int{start: 0}
+	queue := []int{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, next := range adjacent[node] {
+			if _, ok := distances[next]; !ok {
+				distances[next] = distances[node] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return distances
+}
+
+func (g *Graph) adjacency(directed bool) [][]int {
+	adjacent := make([][]int, len(g.Nodes))
+	for _, edge := range g.Edges {
+		adjacent[edge.From] = append(adjacent[edge.From], edge.To)
+		if !directed {
+			adjacent[edge.To] = append(adjacent[edge.To], edge.From)
+		}
+	}
+	return adjacent
+}
+
+func (g *Graph) indexOf(note *Note) int {
+	for i, n := range g.Nodes {
+		if n == note || n.Path == note.Path {
+			return i
+		}
+	}
+	return -1
+}
+
+func (g *Graph) subgraph(kept map[int]bool) *Graph {
+	sub := &Graph{}
+	index := make(map[int]int)
+	for i, note := range g.Nodes {
+		if kept[i] {
+			index[i] = len(sub.Nodes)
+			sub.Nodes = append(sub.Nodes, note)
+		}
+	}
+	for _, edge := range g.Edges {
+		from, ok := index[edge.From]
+		if !ok {
+			continue
+		}
+		to, ok := index[edge.To]
+		if !ok {
+			continue
+		}
+		sub.Edges = append(sub.Edges, Edge{From: from, To: to})
+	}
+	return sub
+}
+
+// TagColor returns the color of the first tag of note, or "" if it has
+// no tags. A tag always gets the same color.
+func TagColor(note *Note) string {
+	tags := ParseTags(note.Body)
+	if len(tags) == 0 {
+		return ""
+	}
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(tags[0])))
+	return tagColors[h.Sum32()%uint32(len(tagColors))]
+}
+
+// WriteGraph writes g to w in format, coloring the nodes by tag when
+// color is set.
+func WriteGraph(w io.Writer, g *Graph, format GraphFormat, color bool) error {
+	colors := make([]string, len(g.Nodes))
+	if color {
+		for i, note := range g.Nodes {
+			colors[i] = TagColor(note)
+		}
+	}
+
+	switch format {
+	case FormatDOT:
+		return writeDOT(w, g, colors)
+	case FormatGraphML:
+		return writeGraphML(w, g, colors)
+	case FormatJSON:
+		return writeGraphJSON(w, g, colors)
+	case FormatCanvas:
+		return writeCanvas(w, g, colors)
+	default:
+		return fmt.Errorf("unknown graph format %q (want dot, graphml, json or canvas)", format)
+	}
+}
+
+func writeDOT(w io.Writer, g *Graph, colors []string) error {
+	var b strings.Builder
+	b.WriteString("digraph zet {\n")
+	b.WriteString("  node [shape=box];\n")
+	for i, note := range g.Nodes {
+		attrs := "label=" + dotQuote(note.Title)
+		if colors[i] != "" {
+			attrs += ", style=filled, fillcolor=" + dotQuote(colors[i])
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(note.Name()), attrs)
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s;\n", dotQuote(g.Nodes[edge.From].Name()), dotQuote(g.Nodes[edge.To].Name()))
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+}
+
+type graphMLDoc struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+func writeGraphML(w io.Writer, g *Graph, colors []string) error {
+	doc := graphMLDoc{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "title", For: "node", Name: "title", Type: "string"},
+			{ID: "path", For: "node", Name: "path", Type: "string"},
+			{ID: "tags", For: "node", Name: "tags", Type: "string"},
+			{ID: "color", For: "node", Name: "color", Type: "string"},
+		},
+	}
+	doc.Graph.ID = "zet"
+	doc.Graph.EdgeDefault = "directed"
+
+	for i, note := range g.Nodes {
+		node := graphMLNode{ID: note.Name(), Data: []graphMLData{
+			{Key: "title", Value: note.Title},
+			{Key: "path", Value: note.Path},
+		}}
+		if tags := ParseTags(note.Body); len(tags) > 0 {
+			node.Data = append(node.Data, graphMLData{Key: "tags", Value: strings.Join(tags, ",")})
+		}
+		if colors[i] != "" {
+			node.Data = append(node.Data, graphMLData{Key: "color", Value: colors[i]})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+	for _, edge := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: g.Nodes[edge.From].Name(),
+			Target: g.Nodes[edge.To].Name(),
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+type jsonGraphNode struct {
+	ID    string   `json:"id"`
+	Title string   `json:"title"`
+	Path  string   `json:"path"`
+	Tags  []string `json:"tags,omitempty"`
+	Color string   `json:"color,omitempty"`
+}
+
+type jsonGraphEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+func writeGraphJSON(w io.Writer, g *Graph, colors []string) error {
+	out := struct {
+		Nodes []jsonGraphNode `json:"nodes"`
+		Edges []jsonGraphEdge `json:"edges"`
+	}{
+		Nodes: []jsonGraphNode{},
+		Edges: []jsonGraphEdge{},
+	}
+	for i, note := range g.Nodes {
+		out.Nodes = append(out.Nodes, jsonGraphNode{
+			ID:    note.Name(),
+			Title: note.Title,
+			Path:  note.Path,
+			Tags:  ParseTags(note.Body),
+			Color: colors[i],
+		})
+	}
+	for _, edge := range g.Edges {
+		out.Edges = append(out.Edges, jsonGraphEdge{
+			Source: g.Nodes[edge.From].Name(),
+			Target: g.Nodes[edge.To].Name(),
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+type canvasNode struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	File   string `json:"file"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Color  string `json:"color,omitempty"`
+}
+
+type canvasEdge struct {
+	ID       string `json:"id"`
+	FromNode string `json:"fromNode"`
+	ToNode   string `json:"toNode"`
+	ToEnd    string `json:"toEnd"`
+}
+
+// writeCanvas writes g as a JSON Canvas laid out on a grid. File nodes
+// refer to notes relative to the vault, where the canvas is expected
+// to be saved.
+func writeCanvas(w io.Writer, g *Graph, colors []string) error {
+	const width, height, gap = 400, 240, 80
+
+	out := struct {
+		Nodes []canvasNode `json:"nodes"`
+		Edges []canvasEdge `json:"edges"`
+	}{
+		Nodes: []canvasNode{},
+		Edges: []canvasEdge{},
+	}
+
+	columns := int(math.Ceil(math.Sqrt(float64(len(g.Nodes)))))
+	for i, note := range g.Nodes {
+		out.Nodes = append(out.Nodes, canvasNode{
+			ID:     note.Name(),
+			Type:   "file",
+			File:   filepath.Base(note.Path),
+			X:      (i % columns) * (width + gap),
+			Y:      (i / columns) * (height + gap),
+			Width:  width,
+			Height: height,
+			Color:  colors[i],
+		})
+	}
+	for i, edge := range g.Edges {
+		out.Edges = append(out.Edges, canvasEdge{
+			ID:       fmt.Sprintf("e%d", i),
+			FromNode: g.Nodes[edge.From].Name(),
+			ToNode:   g.Nodes[edge.To].Name(),
+			ToEnd:    "arrow",
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,193 @@
+package zet_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func graphNotes() []*zet.Note {
+	return []*zet.Note{
+		{Title: "A", Path: "/tmp/A.md", Body: "# A\n#alpha [[B]] [[B#Part]] [[A]] [[Missing]]"},
+		{Title: "B", Path: "/tmp/B.md", Body: "# B\n[C](C.md)"},
+		{Title: "C", Path: "/tmp/C.md", Body: "# C\n#alpha [[D]]"},
+		{Title: "D", Path: "/tmp/D.md", Body: "# D\n"},
+	}
+}
+
+func edgeNames(g *zet.Graph) []string {
+	var edges []string
+	for _, edge := range g.Edges {
+		edges = append(edges, g.Nodes[edge.From].Title+"->"+g.Nodes[edge.To].Title)
+	}
+	return edges
+}
+
+func nodeNames(g *zet.Graph) []string {
+	var nodes []string
+	for _, note := range g.Nodes {
+		nodes = append(nodes, note.Title)
+	}
+	return nodes
+}
+
+func TestBuildGraph(t *testing.T) {
+	g := zet.BuildGraph(graphNotes())
+
+	expected := "A->B,B->C,C->D"
+	if got := strings.Join(edgeNames(g), ","); got != expected {
+		t.Errorf("BuildGraph() edges = %s, want %s", got, expected)
+	}
+}
+
+func TestGraphAround(t *testing.T) {
+	notes := graphNotes()
+	g := zet.BuildGraph(notes)
+
+	tests := []struct {
+		depth         int
+		expectedNodes string
+		expectedEdges string
+	}{
+		{0, "C", ""},
+		{1, "B,C,D", "B->C,C->D"},
+		{2, "A,B,C,D", "A->B,B->C,C->D"},
+	}
+	for _, tt := range tests {
+		sub := g.Around(notes[2], tt.depth)
+		if got := strings.Join(nodeNames(sub), ","); got != tt.expectedNodes {
+			t.Errorf("Around(C, %d) nodes = %s, want %s", tt.depth, got, tt.expectedNodes)
+		}
+		if got := strings.Join(edgeNames(sub), ","); got != tt.expectedEdges {
+			t.Errorf("Around(C, %d) edges = %s, want %s", tt.depth, got, tt.expectedEdges)
+		}
+	}
+}
+
+func TestGraphFilter(t *testing.T) {
+	g := zet.BuildGraph(graphNotes()).Filter(func(note *zet.Note) bool {
+		return zet.HasTag(note, "alpha")
+	})
+
+	if got := strings.Join(nodeNames(g), ","); got != "A,C" {
+		t.Errorf("Filter() nodes = %s, want A,C", got)
+	}
+	if len(g.Edges) != 0 {
+		t.Errorf("Filter() edges = %v, want none", edgeNames(g))
+	}
+}
+
+func TestWriteGraph(t *testing.T) {
+	g := zet.BuildGraph(graphNotes())
+
+	t.Run("dot", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := zet.WriteGraph(&buf, g, zet.FormatDOT, true); err != nil {
+			t.Fatalf("WriteGraph() error = %v", err)
+		}
+		out := buf.String()
+		color := zet.TagColor(g.Nodes[0])
+		for _, want := range []string{
+			"digraph zet {",
+			`"A" [label="A", style=filled, fillcolor="` + color + `"];`,
+			`"B" [label="B"];`,
+			`"A" -> "B";`,
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("DOT output missing %q:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("graphml", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := zet.WriteGraph(&buf, g, zet.FormatGraphML, false); err != nil {
+			t.Fatalf("WriteGraph() error = %v", err)
+		}
+		var doc struct {
+			Graph struct {
+				Nodes []struct {
+					ID string `xml:"id,attr"`
+				} `xml:"node"`
+				Edges []struct {
+					Source string `xml:"source,attr"`
+					Target string `xml:"target,attr"`
+				} `xml:"edge"`
+			} `xml:"graph"`
+		}
+		if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("invalid GraphML: %v", err)
+		}
+		if len(doc.Graph.Nodes) != 4 || len(doc.Graph.Edges) != 3 {
+			t.Errorf("GraphML has %d nodes and %d edges, want 4 and 3", len(doc.Graph.Nodes), len(doc.Graph.Edges))
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := zet.WriteGraph(&buf, g, zet.FormatJSON, false); err != nil {
+			t.Fatalf("WriteGraph() error = %v", err)
+		}
+		var out struct {
+			Nodes []struct {
+				ID   string   `json:"id"`
+				Tags []string `json:"tags"`
+			} `json:"nodes"`
+			Edges []struct {
+				Source string `json:"source"`
+				Target string `json:"target"`
+			} `json:"edges"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if out.Nodes[0].ID != "A" || len(out.Nodes[0].Tags) != 1 {
+			t.Errorf("first node = %+v, want A tagged alpha", out.Nodes[0])
+		}
+		if out.Edges[2].Source != "C" || out.Edges[2].Target != "D" {
+			t.Errorf("last edge = %+v, want C -> D", out.Edges[2])
+		}
+	})
+
+	t.Run("canvas", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := zet.WriteGraph(&buf, g, zet.FormatCanvas, false); err != nil {
+			t.Fatalf("WriteGraph() error = %v", err)
+		}
+		var out struct {
+			Nodes []struct {
+				ID   string `json:"id"`
+				Type string `json:"type"`
+				File string `json:"file"`
+				X    int    `json:"x"`
+				Y    int    `json:"y"`
+			} `json:"nodes"`
+			Edges []struct {
+				FromNode string `json:"fromNode"`
+				ToNode   string `json:"toNode"`
+			} `json:"edges"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+			t.Fatalf("invalid canvas: %v", err)
+		}
+		if out.Nodes[1].Type != "file" || out.Nodes[1].File != "B.md" {
+			t.Errorf("second node = %+v, want file B.md", out.Nodes[1])
+		}
+		if out.Nodes[0].X == out.Nodes[1].X && out.Nodes[0].Y == out.Nodes[1].Y {
+			t.Error("canvas nodes overlap")
+		}
+		if len(out.Edges) != 3 || out.Edges[0].FromNode != "A" {
+			t.Errorf("canvas edges = %+v", out.Edges)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		if err := zet.WriteGraph(&bytes.Buffer{}, g, "svg", false); err == nil {
+			t.Error("WriteGraph() with unknown format should fail")
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package zet
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+type HookEvent string
+
+const (
+	HookCreated   HookEvent = "created"
+	HookEdited    HookEvent = "edited"
+	HookDeleted   HookEvent = "deleted"
+	HookRenamed   HookEvent = "renamed"
+	HookPreDelete HookEvent = "pre-delete"
+	HookPostEdit  HookEvent = "post-edit"
+)
+
+// RunHook runs the executable named after event in the hooks directory
+// of the vault containing path, if there is one. The note is passed in
+// the ZET_NOTE_PATH, ZET_NOTE_TITLE and, for renames, ZET_OLD_PATH
+// environment variables. A hook that exits non-zero returns an error.
+func RunHook(event HookEvent, path, title, oldPath string) error {
+	dir := filepath.Dir(path)
+	hook := filepath.Join(GetHooksDir(dir), string(event))
+
+	info, err := os.Stat(hook)
+	if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+		return nil
+	}
+
+	cmd := exec.Command(hook)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"ZET_EVENT="+string(event),
+		"ZET_NOTE_PATH="+path,
+		"ZET_NOTE_TITLE="+title,
+		"ZET_OLD_PATH="+oldPath,
+	)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s hook: %w", event, err)
+	}
+	return nil
+}
+
+// fireHook runs a hook whose outcome cannot change what zet does,
+// reporting failures without returning them. Hooks for note changes are
+// left to zet watch when it is running on the vault, so that they fire
+// once.
+func fireHook(event HookEvent, path, title, oldPath string) {
+	switch event {
+	case HookCreated, HookEdited, HookDeleted, HookRenamed:
+		if WatcherRunning(filepath.Dir(path)) {
+			return
+		}
+	}
+	if err := RunHook(event, path, title, oldPath); err != nil {
+		fmt.Fprintf(os.Stderr, "zet: %v\n", err)
+	}
+}
+
+// WatchHooks fires the created, edited, deleted and renamed hooks for
+// changes made to the notes in dir, by zet or anything else, until stop
+// is closed. While it runs, zet itself leaves those hooks to it.
+func WatchHooks(dir string, stop <-chan struct{}) error {
+	marker := watcherMarker(dir)
+	pid := strconv.Itoa(os.Getpid())
+	defer func() {
+		if content, err := os.ReadFile(marker); err == nil && strings.TrimSpace(string(content)) == pid {
+			os.Remove(marker)
+		}
+	}()
+
+	ready := func() {
+		if err := os.WriteFile(marker, []byte(pid+"\n"), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "zet: %v\n", err)
+		}
+	}
+	return watch(dir, stop, ready, func(events []NoteEvent) {
+		for _, event := range events {
+			title := (&Note{Path: event.Path}).Name()
+			if note, err := ReadNote(event.Path); err == nil {
+				title = note.Title
+			}
+			if err := RunHook(HookEvent(event.Kind), event.Path, title, event.OldPath); err != nil {
+				fmt.Fprintf(os.Stderr, "zet: %v\n", err)
+			}
+		}
+	})
+}
+
+// watcherMarker returns the file holding the pid of the zet watch
+// running on the vault in dir.
+func watcherMarker(dir string) string {
+	return filepath.Join(runtimeDir(), "zet-"+vaultKey(dir)+".watch")
+}
+
+// WatcherRunning reports whether a zet watch is running on the vault in
+// dir on this machine.
+func WatcherRunning(dir string) bool {
+	content, err := os.ReadFile(watcherMarker(dir))
+	if err != nil {
+		return false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	return err == nil && process.Signal(syscall.Signal(0)) == nil
+}
@@ -0,0 +1,203 @@
+package zet_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func writeHook(t *testing.T, zetDir string, event zet.HookEvent, script string) {
+	t.Helper()
+	hooksDir := zet.GetHooksDir(zetDir)
+	err := os.MkdirAll(hooksDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(hooksDir, string(event)), []byte("#!/bin/sh\n"+script+"\n"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunHook(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	logPath := filepath.Join(zetDir, "hook.log")
+	writeHook(t, zetDir, zet.HookRenamed, `echo "$ZET_EVENT|$ZET_NOTE_TITLE|$ZET_NOTE_PATH|$ZET_OLD_PATH" > `+logPath)
+
+	notePath := filepath.Join(zetDir, "New.md")
+	err := zet.RunHook(zet.HookRenamed, notePath, "New", filepath.Join(zetDir, "Old.md"))
+	if err != nil {
+		t.Fatalf("RunHook() error = %v", err)
+	}
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "renamed|New|" + notePath + "|" + filepath.Join(zetDir, "Old.md")
+	if strings.TrimSpace(string(content)) != expected {
+		t.Errorf("hook saw %q, want %q", strings.TrimSpace(string(content)), expected)
+	}
+}
+
+func TestRunHookMissing(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	err := zet.RunHook(zet.HookCreated, filepath.Join(zetDir, "Note.md"), "Note", "")
+	if err != nil {
+		t.Errorf("RunHook() without a hook should not fail, got %v", err)
+	}
+}
+
+func TestCreateOrEditNoteFiresCreatedHook(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	originalZetDir := os.Getenv("ZETDIR")
+	os.Setenv("ZETDIR", zetDir)
+	defer func() {
+		if originalZetDir != "" {
+			os.Setenv("ZETDIR", originalZetDir)
+		} else {
+			os.Unsetenv("ZETDIR")
+		}
+	}()
+
+	logPath := filepath.Join(zetDir, "hook.log")
+	writeHook(t, zetDir, zet.HookCreated, `echo "$ZET_NOTE_TITLE" >> `+logPath)
+
+	for i := 0; i < 2; i++ {
+		_, err := zet.CreateOrEditNote("Hooked Note")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "Hooked Note\n" {
+		t.Errorf("created hook log = %q, want a single entry", string(content))
+	}
+}
+
+func TestDeleteNoteAbortedByPreDeleteHook(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	writeHook(t, zetDir, zet.HookPreDelete, "exit 1")
+
+	notePath := filepath.Join(zetDir, "Keep Me.md")
+	err := os.WriteFile(notePath, []byte("content"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = zet.DeleteNote(&zet.Note{Title: "Keep Me", Path: notePath})
+	if err == nil {
+		t.Error("DeleteNote() should fail when the pre-delete hook fails")
+	}
+
+	_, err = os.Stat(notePath)
+	if err != nil {
+		t.Errorf("note should still exist after aborted delete: %v", err)
+	}
+}
+
+func TestWatchHooks(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	logPath := filepath.Join(zetDir, "hook.log")
+	writeHook(t, zetDir, zet.HookEdited, `echo "$ZET_NOTE_TITLE" >> `+logPath)
+
+	notePath := filepath.Join(zetDir, "Watched.md")
+	err := os.WriteFile(notePath, []byte("before"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := make(chan struct{})
+	done := make(chan error, 1)
+	go func() { done <- zet.WatchHooks(zetDir, stop) }()
+	defer func() {
+		close(stop)
+		if err := <-done; err != nil {
+			t.Errorf("WatchHooks() error = %v", err)
+		}
+	}()
+
+	// Edit every half second until the watcher, which may not have taken
+	// its initial snapshot yet, notices a change
+	polls := 0
+	waitFor(t, func() bool {
+		if polls%25 == 0 {
+			os.WriteFile(notePath, []byte(strings.Repeat("after ", polls+1)), 0644)
+		}
+		polls++
+		_, err := os.Stat(logPath)
+		return err == nil
+	})
+}
+
+func TestWatchHooksFiresOnce(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	originalZetDir := os.Getenv("ZETDIR")
+	os.Setenv("ZETDIR", zetDir)
+	defer func() {
+		if originalZetDir != "" {
+			os.Setenv("ZETDIR", originalZetDir)
+		} else {
+			os.Unsetenv("ZETDIR")
+		}
+	}()
+
+	logDir := ZetDir(t)
+	defer Cleanup(t, logDir)
+	logPath := filepath.Join(logDir, "hook.log")
+	writeHook(t, zetDir, zet.HookCreated, `echo "$ZET_NOTE_TITLE" >> `+logPath)
+
+	stop := make(chan struct{})
+	done := make(chan error, 1)
+	go func() { done <- zet.WatchHooks(zetDir, stop) }()
+	waitFor(t, func() bool { return zet.WatcherRunning(zetDir) })
+
+	_, err := zet.CreateOrEditNote("Once")
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		_, err := os.Stat(logPath)
+		return err == nil
+	})
+	// Give a second firing time to show up
+	time.Sleep(time.Second)
+
+	close(stop)
+	if err := <-done; err != nil {
+		t.Errorf("WatchHooks() error = %v", err)
+	}
+	if zet.WatcherRunning(zetDir) {
+		t.Error("WatcherRunning() after the watcher stopped = true, want false")
+	}
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "Once\n" {
+		t.Errorf("created hook log = %q, want a single entry", string(content))
+	}
+}
@@ -0,0 +1,153 @@
+package zet
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Index holds parsed notes along with their links and a term index so
+// that searches and backlink queries do not have to rescan the vault.
+type Index struct {
+	Notes []*Note
+
+	links     map[string][]Link
+	backlinks map[string][]*Note
+	terms     map[string]map[int]int
+}
+
+type SearchHit struct {
+	Note *Note
+	Line int    // 1-based line number of the match
+	Col  int    // 1-based byte column of the first matching term
+	Text string // The matching line
+}
+
+func BuildIndex(notes []*Note) *Index {
+	ix := &Index{
+		Notes:     notes,
+		links:     make(map[string][]Link),
+		backlinks: make(map[string][]*Note),
+		terms:     make(map[string]map[int]int),
+	}
+
+	r := newResolver(notes)
+	for i, note := range notes {
+		links := ParseLinks(note.Body)
+		ix.links[note.Path] = links
+
+		seen := make(map[string]bool)
+		for _, link := range links {
+			target := r.lookup(link.Target)
+			if target == nil || target == note || seen[target.Path] {
+				continue
+			}
+			seen[target.Path] = true
+			ix.backlinks[target.Path] = append(ix.backlinks[target.Path], note)
+		}
+
+		for _, term := range Tokenize(note.Title + "\n" + note.Body) {
+			if ix.terms[term] == nil {
+				ix.terms[term] = make(map[int]int)
+			}
+			ix.terms[term][i]++
+		}
+	}
+
+	return ix
+}
+
+// Links returns the outgoing links of note.
+func (ix *Index) Links(note *Note) []Link {
+	return ix.links[note.Path]
+}
+
+// Backlinks returns the notes linking to note.
+func (ix *Index) Backlinks(note *Note) []*Note {
+	return ix.backlinks[note.Path]
+}
+
+// Search returns the notes containing every term of query, where each
+// query term may match the prefix of a word in the note. Results are
+// ordered by how often the terms occur, then by title.
+func (ix *Index) Search(query string) []*Note {
+	queryTerms := Tokenize(query)
+	if len(queryTerms) == 0 {
+		return nil
+	}
+
+	var scores map[int]int
+	for _, qt := range queryTerms {
+		matched := make(map[int]int)
+		for term, postings := range ix.terms {
+			if !strings.HasPrefix(term, qt) {
+				continue
+			}
+			for i, count := range postings {
+				matched[i] += count
+			}
+		}
+
+		if scores == nil {
+			scores = matched
+			continue
+		}
+		for i := range scores {
+			if count, ok := matched[i]; ok {
+				scores[i] += count
+			} else {
+				delete(scores, i)
+			}
+		}
+	}
+
+	var result []int
+	for i := range scores {
+		result = append(result, i)
+	}
+	sort.Slice(result, func(a, b int) bool {
+		if scores[result[a]] != scores[result[b]] {
+			return scores[result[a]] > scores[result[b]]
+		}
+		return ix.Notes[result[a]].Title < ix.Notes[result[b]].Title
+	})
+
+	notes := make([]*Note, len(result))
+	for i, idx := range result {
+		notes[i] = ix.Notes[idx]
+	}
+	return notes
+}
+
+// SearchHits returns the lines of notes that contain any term of query.
+func SearchHits(notes []*Note, query string) []SearchHit {
+	queryTerms := Tokenize(query)
+
+	var hits []SearchHit
+	for _, note := range notes {
+		for i, line := range strings.Split(note.Body, "\n") {
+			lower := strings.ToLower(line)
+			for _, term := range queryTerms {
+				index := strings.Index(lower, term)
+				if index < 0 {
+					continue
+				}
+				// Lower-casing keeps the number of runes but not of bytes
+				runes := utf8.RuneCountInString(lower[:index])
+				col := len(string([]rune(line)[:runes])) + 1
+				hits = append(hits, SearchHit{Note: note, Line: i + 1, Col: col, Text: line})
+				break
+			}
+		}
+	}
+	return hits
+}
+
+// Tokenize splits text into lower-cased words made of letters and
+// digits from any script.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,71 @@
+package zet_test
+
+import (
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestIndexSearch(t *testing.T) {
+	notes := []*zet.Note{
+		{Title: "Apple", Path: "/tmp/Apple.md", Body: "fruit that grows on trees"},
+		{Title: "Banana", Path: "/tmp/Banana.md", Body: "fruit fruit fruit, yellow"},
+		{Title: "Oak", Path: "/tmp/Oak.md", Body: "a tree"},
+	}
+	ix := zet.BuildIndex(notes)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{name: "ranked by frequency", query: "fruit", expected: []string{"Banana", "Apple"}},
+		{name: "prefix match", query: "tree", expected: []string{"Apple", "Oak"}},
+		{name: "all terms required", query: "fruit yellow", expected: []string{"Banana"}},
+		{name: "matches title", query: "oak", expected: []string{"Oak"}},
+		{name: "no match", query: "granite", expected: nil},
+		{name: "empty query", query: "", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ix.Search(tt.query)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("Search(%q) returned %d notes, want %d", tt.query, len(result), len(tt.expected))
+			}
+			for i, note := range result {
+				if note.Title != tt.expected[i] {
+					t.Errorf("Search(%q)[%d] = %q, want %q", tt.query, i, note.Title, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSearchHits(t *testing.T) {
+	notes := []*zet.Note{
+		{Title: "Apple", Path: "/tmp/Apple.md", Body: "first line\nsecond Fruit line\nthird"},
+	}
+
+	hits := zet.SearchHits(notes, "fruit")
+	if len(hits) != 1 {
+		t.Fatalf("SearchHits() returned %d hits, want 1", len(hits))
+	}
+	if hits[0].Line != 2 || hits[0].Col != 8 || hits[0].Text != "second Fruit line" {
+		t.Errorf("SearchHits()[0] = %+v, want line 2", hits[0])
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	result := zet.Tokenize("Über Café, naïve-approach 42")
+	expected := []string{"über", "café", "naïve", "approach", "42"}
+
+	if len(result) != len(expected) {
+		t.Fatalf("Tokenize() = %q, want %q", result, expected)
+	}
+	for i := range result {
+		if result[i] != expected[i] {
+			t.Errorf("Tokenize()[%d] = %q, want %q", i, result[i], expected[i])
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package zet
+
+import (
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type Link struct {
+	Target  string // Note name or path as written in the link
+	Heading string // Text after '#' in the target, if any
+	Block   string // Block id after '#^' in the target, if any
+	Label   string // Display text, if any
+	Embed   bool   // True for ![[...]] embeds
+	Line    int    // 1-based line number of the link in the body
+	Start   int    // Byte offset of the link in the body
+	End     int    // Byte offset just past the link in the body
+}
+
+var (
+	wikiLinkRe     = regexp.MustCompile(`(!?)\[\[([^\[\]\n]+)\]\]`)
+	markdownLinkRe = regexp.MustCompile(`\[([^\[\]\n]*)\]\(([^()\s]+\.md)(#[^()\s]*)?\)`)
+)
+
+// ParseLinks returns the wikilinks, embeds and relative markdown links
+// to notes found in body, skipping fenced code blocks and inline code.
+func ParseLinks(body string) []Link {
+	var links []Link
+
+	offset := 0
+	for i, line := range CodeMaskedLines(body) {
+		for _, m := range wikiLinkRe.FindAllStringSubmatchIndex(line, -1) {
+			inner := line[m[4]:m[5]]
+			link := Link{
+				Embed: m[3] > m[2],
+				Line:  i + 1,
+				Start: offset + m[0],
+				End:   offset + m[1],
+			}
+			if target, label, ok := strings.Cut(inner, "|"); ok {
+				inner, link.Label = target, strings.TrimSpace(label)
+			}
+			target, anchor, _ := strings.Cut(inner, "#")
+			link.Target = strings.TrimSpace(target)
+			link.Heading, link.Block = splitAnchor(anchor)
+			links = append(links, link)
+		}
+
+		for _, m := range markdownLinkRe.FindAllStringSubmatchIndex(line, -1) {
+			if m[0] > 0 && line[m[0]-1] == '!' {
+				continue
+			}
+			target := line[m[4]:m[5]]
+			if strings.Contains(target, "://") {
+				continue
+			}
+			if unescaped, err := url.PathUnescape(target); err == nil {
+				target = unescaped
+			}
+			link := Link{
+				Target: strings.TrimSuffix(filepath.Base(target), ".md"),
+				Label:  line[m[2]:m[3]],
+				Line:   i + 1,
+				Start:  offset + m[0],
+				End:    offset + m[1],
+			}
+			if m[6] >= 0 {
+				anchor := strings.TrimPrefix(line[m[6]:m[7]], "#")
+				if unescaped, err := url.PathUnescape(anchor); err == nil {
+					anchor = unescaped
+				}
+				link.Heading, link.Block = splitAnchor(anchor)
+			}
+			links = append(links, link)
+		}
+
+		offset += len(line) + 1
+	}
+
+	return links
+}
+
+func splitAnchor(anchor string) (string, string) {
+	anchor = strings.TrimSpace(anchor)
+	if strings.HasPrefix(anchor, "^") {
+		return "", anchor[1:]
+	}
+	return anchor, ""
+}
+
+// EditLinks returns body with each link replaced by the text edit
+// returns for it, when edit reports a change.
+func EditLinks(body string, edit func(link Link, text string) (string, bool)) string {
+	var b strings.Builder
+	last := 0
+	for _, link := range ParseLinks(body) {
+		replacement, ok := edit(link, body[link.Start:link.End])
+		if !ok {
+			continue
+		}
+		b.WriteString(body[last:link.Start])
+		b.WriteString(replacement)
+		last = link.End
+	}
+	b.WriteString(body[last:])
+	return b.String()
+}
+
+// RewriteLinks returns body with every link for which match returns
+// true pointed at the note to, keeping anchors, labels and embeds.
+// Wikilinks are rewritten to the title of to and markdown links to its
+// filename.
+func RewriteLinks(body string, match func(Link) bool, to *Note) string {
+	return EditLinks(body, func(link Link, text string) (string, bool) {
+		if !match(link) {
+			return "", false
+		}
+		return FormatLink(text, link, to), true
+	})
+}
+
+// FormatLink returns link, originally written as text, as a link to the
+// note to in the same style.
+func FormatLink(text string, link Link, to *Note) string {
+	if strings.HasSuffix(text, ")") {
+		target := url.PathEscape(filepath.Base(to.Path))
+		if anchor := link.Anchor(); anchor != "" {
+			target += "#" + url.PathEscape(anchor)
+		}
+		return "[" + link.Label + "](" + target + ")"
+	}
+
+	link.Target = to.Title
+	return FormatWikiLink(link)
+}
+
+// Anchor returns the part of the link target after '#', if any.
+func (l Link) Anchor() string {
+	if l.Block != "" {
+		return "^" + l.Block
+	}
+	return l.Heading
+}
+
+// FormatWikiLink returns the [[...]] or ![[...]] text for link.
+func FormatWikiLink(link Link) string {
+	text := link.Target
+	if anchor := link.Anchor(); anchor != "" {
+		text += "#" + anchor
+	}
+	if link.Label != "" {
+		text += "|" + link.Label
+	}
+	if link.Embed {
+		return "![[" + text + "]]"
+	}
+	return "[[" + text + "]]"
+}
+
+// CodeMaskedLines splits body into lines with fenced code blocks and
+// inline code spans replaced by spaces so that offsets are preserved
+// but nothing inside code is treated as markup.
+func CodeMaskedLines(body string) []string {
+	lines := strings.Split(body, "\n")
+	fence := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			lines[i] = blank(line)
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			lines[i] = blank(line)
+			continue
+		}
+		lines[i] = maskInlineCode(line)
+	}
+	return lines
+}
+
+func maskInlineCode(line string) string {
+	if !strings.Contains(line, "`") {
+		return line
+	}
+	b := []byte(line)
+	for start := 0; start < len(b); start++ {
+		if b[start] != '`' {
+			continue
+		}
+		end := strings.IndexByte(line[start+1:], '`')
+		if end < 0 {
+			break
+		}
+		end += start + 1
+		for j := start; j <= end; j++ {
+			b[j] = ' '
+		}
+		start = end
+	}
+	return string(b)
+}
+
+func blank(s string) string {
+	return strings.Repeat(" ", len(s))
+}
+
+// LookupNote returns the note whose title or filename matches name, or
+// nil if there is none.
+func LookupNote(notes []*Note, name string) *Note {
+	return newResolver(notes).lookup(name)
+}
+
+// resolver maps the names a note can be referred to by onto the note.
+// Exact matches win over case-insensitive ones, and titles and
+// filenames win over aliases.
+type resolver struct {
+	exact  map[string]*Note
+	folded map[string]*Note
+}
+
+func newResolver(notes []*Note) *resolver {
+	r := &resolver{
+		exact:  make(map[string]*Note),
+		folded: make(map[string]*Note),
+	}
+	add := func(name string, note *Note) {
+		if _, ok := r.exact[name]; !ok {
+			r.exact[name] = note
+		}
+		if _, ok := r.folded[strings.ToLower(name)]; !ok {
+			r.folded[strings.ToLower(name)] = note
+		}
+	}
+	for _, note := range notes {
+		add(note.Title, note)
+		add(note.Name(), note)
+	}
+	for _, note := range notes {
+		for _, alias := range NoteAliases(note) {
+			add(alias, note)
+		}
+	}
+	return r
+}
+
+// lookup returns the note name is the title, filename or alias of. Its
+// sanitized filename form is not tried: that would be the file of
+// another title as often as of this one, so [[C++]] would resolve to
+// the note C, and a note reached that way is only right when name is
+// its title, which the match on name itself already finds.
+func (r *resolver) lookup(name string) *Note {
+	name = strings.TrimSuffix(strings.TrimSpace(name), ".md")
+	if name == "" {
+		return nil
+	}
+	if note, ok := r.exact[name]; ok {
+		return note
+	}
+	return r.folded[strings.ToLower(name)]
+}
+
+// Backlinks returns the notes that link to target, in the order given.
+func Backlinks(notes []*Note, target *Note) []*Note {
+	return BuildIndex(notes).Backlinks(target)
+}
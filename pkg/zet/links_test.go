@@ -0,0 +1,137 @@
+package zet_test
+
+import (
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestParseLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []zet.Link
+	}{
+		{
+			name:     "wikilink",
+			body:     "See [[Other Note]] for more",
+			expected: []zet.Link{{Target: "Other Note", Line: 1, Start: 4, End: 18}},
+		},
+		{
+			name:     "wikilink with heading and label",
+			body:     "intro\n[[Other Note#Details|the details]]",
+			expected: []zet.Link{{Target: "Other Note", Heading: "Details", Label: "the details", Line: 2, Start: 6, End: 40}},
+		},
+		{
+			name:     "block reference",
+			body:     "[[Other#^quote-1]]",
+			expected: []zet.Link{{Target: "Other", Block: "quote-1", Line: 1, Start: 0, End: 18}},
+		},
+		{
+			name:     "same note heading",
+			body:     "[[#Details]]",
+			expected: []zet.Link{{Heading: "Details", Line: 1, Start: 0, End: 12}},
+		},
+		{
+			name:     "embed",
+			body:     "![[Snippet]]",
+			expected: []zet.Link{{Target: "Snippet", Embed: true, Line: 1, Start: 0, End: 12}},
+		},
+		{
+			name:     "markdown link",
+			body:     "[other](Other%20Note.md)",
+			expected: []zet.Link{{Target: "Other Note", Label: "other", Line: 1, Start: 0, End: 24}},
+		},
+		{
+			name:     "external markdown link is ignored",
+			body:     "[site](https://example.com/page.md)",
+			expected: nil,
+		},
+		{
+			name:     "links in code are ignored",
+			body:     "```\n[[Not A Link]]\n```\n`[[Nor This]]`",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := zet.ParseLinks(tt.body)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("ParseLinks() returned %d links, want %d: %+v", len(result), len(tt.expected), result)
+			}
+			for i := range result {
+				if result[i] != tt.expected[i] {
+					t.Errorf("ParseLinks()[%d] = %+v, want %+v", i, result[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLookupNote(t *testing.T) {
+	notes := []*zet.Note{
+		{Title: "Apple Note", Path: "/tmp/Apple Note.md"},
+		{Title: "What's the plan?", Path: "/tmp/Whats the plan.md"},
+		{Title: "C", Path: "/tmp/C.md"},
+		{Title: "Command Line Interface", Path: "/tmp/Command Line Interface.md", Body: "---\naliases: [CLI, Apple Note]\n---\n"},
+	}
+
+	tests := []struct {
+		name     string
+		search   string
+		expected *zet.Note
+	}{
+		{name: "by title", search: "Apple Note", expected: notes[0]},
+		{name: "by filename", search: "Apple Note.md", expected: notes[0]},
+		{name: "by preserved title", search: "What's the plan?", expected: notes[1]},
+		{name: "by sanitized filename", search: "Whats the plan", expected: notes[1]},
+		{name: "not by another title's filename", search: "C++", expected: nil},
+		{name: "case insensitive", search: "apple note", expected: notes[0]},
+		{name: "by alias", search: "CLI", expected: notes[3]},
+		{name: "alias case insensitive", search: "cli", expected: notes[3]},
+		{name: "title wins over alias", search: "Apple Note", expected: notes[0]},
+		{name: "missing", search: "Banana", expected: nil},
+		{name: "empty", search: "", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := zet.LookupNote(notes, tt.search)
+			if result != tt.expected {
+				t.Errorf("LookupNote(%q) = %v, want %v", tt.search, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBacklinks(t *testing.T) {
+	notes := []*zet.Note{
+		{Title: "A", Path: "/tmp/A.md", Body: "links to [[B]] and [[B]]"},
+		{Title: "B", Path: "/tmp/B.md", Body: "links to [[B]] itself"},
+		{Title: "C", Path: "/tmp/C.md", Body: "[b](B.md)"},
+	}
+
+	result := zet.Backlinks(notes, notes[1])
+	if len(result) != 2 || result[0] != notes[0] || result[1] != notes[2] {
+		t.Errorf("Backlinks() = %v, want [A C]", result)
+	}
+}
+
+func TestFormatWikiLink(t *testing.T) {
+	tests := []struct {
+		link     zet.Link
+		expected string
+	}{
+		{link: zet.Link{Target: "Note"}, expected: "[[Note]]"},
+		{link: zet.Link{Target: "Note", Heading: "Part", Label: "p"}, expected: "[[Note#Part|p]]"},
+		{link: zet.Link{Target: "Note", Block: "id", Embed: true}, expected: "![[Note#^id]]"},
+	}
+
+	for _, tt := range tests {
+		result := zet.FormatWikiLink(tt.link)
+		if result != tt.expected {
+			t.Errorf("FormatWikiLink(%+v) = %q, want %q", tt.link, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package zet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// NoteLock is an advisory lock held on a note while it is being edited.
+// Lock files live in the vault so that other machines syncing it can
+// see them too.
+type NoteLock struct {
+	Path string // Path of the lock file
+}
+
+type LockedError struct {
+	Note   string
+	Holder string // "host pid" of the process holding the lock
+	Since  time.Time
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s is being edited by %s since %s",
+		filepath.Base(e.Note), e.Holder, e.Since.Format("2006-01-02 15:04"))
+}
+
+func GetLocksDir(dir string) string {
+	return filepath.Join(GetVaultConfigDir(dir), "locks")
+}
+
+// LockNote takes the edit lock of the note at path, returning a
+// *LockedError if another live process holds it. Locks left behind by
+// dead processes on this host are reclaimed.
+func LockNote(path string) (*NoteLock, error) {
+	locksDir := GetLocksDir(filepath.Dir(path))
+	err := os.MkdirAll(locksDir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &NoteLock{Path: filepath.Join(locksDir, filepath.Base(path)+".lock")}
+	host, _ := os.Hostname()
+	holder := fmt.Sprintf("%s %d", host, os.Getpid())
+
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := os.OpenFile(lock.Path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = file.WriteString(holder + "\n")
+			file.Close()
+			return lock, err
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		content, err := os.ReadFile(lock.Path)
+		if err != nil {
+			continue
+		}
+		info, err := os.Stat(lock.Path)
+		if err != nil {
+			continue
+		}
+
+		current := strings.TrimSpace(string(content))
+		if !lockIsStale(current, host) {
+			return nil, &LockedError{Note: path, Holder: current, Since: info.ModTime()}
+		}
+		os.Remove(lock.Path)
+	}
+
+	return nil, fmt.Errorf("could not lock %s", path)
+}
+
+// Unlock releases the lock.
+func (l *NoteLock) Unlock() error {
+	return os.Remove(l.Path)
+}
+
+func lockIsStale(holder, host string) bool {
+	lockHost, pidText, ok := strings.Cut(holder, " ")
+	if !ok || lockHost != host {
+		return false
+	}
+	pid, err := strconv.Atoi(pidText)
+	if err != nil {
+		return true
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return true
+	}
+	return process.Signal(syscall.Signal(0)) != nil
+}
@@ -0,0 +1,66 @@
+package zet_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestLockNote(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	notePath := filepath.Join(zetDir, "Locked.md")
+
+	lock, err := zet.LockNote(notePath)
+	if err != nil {
+		t.Fatalf("LockNote() error = %v", err)
+	}
+
+	_, err = zet.LockNote(notePath)
+	var locked *zet.LockedError
+	if !errors.As(err, &locked) {
+		t.Errorf("second LockNote() error = %v, want *LockedError", err)
+	}
+
+	err = lock.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err = zet.LockNote(notePath)
+	if err != nil {
+		t.Fatalf("LockNote() after Unlock() error = %v", err)
+	}
+	lock.Unlock()
+}
+
+func TestLockNoteReclaimsStaleLock(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	notePath := filepath.Join(zetDir, "Stale.md")
+	locksDir := zet.GetLocksDir(zetDir)
+	err := os.MkdirAll(locksDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// No process has a pid this large
+	host, _ := os.Hostname()
+	stale := fmt.Sprintf("%s %d\n", host, 1<<30)
+	err = os.WriteFile(filepath.Join(locksDir, "Stale.md.lock"), []byte(stale), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := zet.LockNote(notePath)
+	if err != nil {
+		t.Fatalf("LockNote() over stale lock error = %v", err)
+	}
+	lock.Unlock()
+}
@@ -0,0 +1,255 @@
+package zet
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+type Heading struct {
+	Level int    // Number of leading '#'
+	Text  string // Heading text without the markers
+	Line  int    // 1-based line number
+}
+
+// ParseHeadings returns the ATX headings of body outside code blocks.
+func ParseHeadings(body string) []Heading {
+	var headings []Heading
+	for i, line := range CodeMaskedLines(body) {
+		level := 0
+		for level < len(line) && line[level] == '#' {
+			level++
+		}
+		if level == 0 || level > 6 || level < len(line) && line[level] != ' ' {
+			continue
+		}
+		text := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(line[level:]), "#"))
+		if text == "" {
+			continue
+		}
+		headings = append(headings, Heading{Level: level, Text: text, Line: i + 1})
+	}
+	return headings
+}
+
+// SectionContent returns the section of body under the heading whose
+// text is heading, from the heading line up to the next heading of the
+// same or a higher level.
+func SectionContent(body, heading string) (string, bool) {
+	headings := ParseHeadings(body)
+	section := findSection(headings, heading)
+	if section == nil {
+		return "", false
+	}
+
+	lines := strings.Split(body, "\n")
+	end := sectionEnd(headings, section, len(lines))
+	return strings.Join(lines[section.Line-1:end], "\n"), true
+}
+
+type Block struct {
+	ID   string // Block id without the leading '^'
+	Line int    // 1-based line number
+}
+
+var blockIDRe = regexp.MustCompile(`(?:^|\s)\^([A-Za-z0-9-]+)\s*$`)
+
+// ParseBlocks returns the ^block-id anchors ending lines of body outside
+// code blocks.
+func ParseBlocks(body string) []Block {
+	var blocks []Block
+	for i, line := range CodeMaskedLines(body) {
+		if m := blockIDRe.FindStringSubmatch(line); m != nil {
+			blocks = append(blocks, Block{ID: m[1], Line: i + 1})
+		}
+	}
+	return blocks
+}
+
+// BlockContent returns the paragraph of body ending in the ^id anchor,
+// without the anchor.
+func BlockContent(body, id string) (string, bool) {
+	for _, block := range ParseBlocks(body) {
+		if block.ID != id {
+			continue
+		}
+		lines := strings.Split(body, "\n")
+		start := block.Line - 1
+		for start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+			start--
+		}
+		paragraph := append([]string{}, lines[start:block.Line]...)
+		last := len(paragraph) - 1
+		paragraph[last] = strings.TrimRight(strings.TrimSuffix(strings.TrimSpace(paragraph[last]), "^"+id), " ")
+		return strings.Join(paragraph, "\n"), true
+	}
+	return "", false
+}
+
+// AnchorLine returns the line of body that the heading or block anchor
+// of link refers to, 1 when the link has no anchor, and false when the
+// anchor cannot be found.
+func AnchorLine(body string, link Link) (int, bool) {
+	switch {
+	case link.Block != "":
+		for _, block := range ParseBlocks(body) {
+			if block.ID == link.Block {
+				return block.Line, true
+			}
+		}
+		return 0, false
+	case link.Heading != "":
+		section := findSection(ParseHeadings(body), link.Heading)
+		if section == nil {
+			return 0, false
+		}
+		return section.Line, true
+	}
+	return 1, true
+}
+
+// HeadingRenames pairs the headings of before that are gone from after
+// with the heading at the same position and level in after, returning
+// a map from old to new heading text.
+func HeadingRenames(before, after string) map[string]string {
+	old, current := ParseHeadings(before), ParseHeadings(after)
+	if len(old) != len(current) {
+		return nil
+	}
+
+	remaining := make(map[string]bool)
+	for _, heading := range current {
+		remaining[strings.ToLower(heading.Text)] = true
+	}
+
+	renames := make(map[string]string)
+	for i := range old {
+		if old[i].Level != current[i].Level || old[i].Text == current[i].Text {
+			continue
+		}
+		if remaining[strings.ToLower(old[i].Text)] {
+			continue
+		}
+		renames[old[i].Text] = current[i].Text
+	}
+	return renames
+}
+
+type InsertOptions struct {
+	Prepend   bool      // Insert at the top instead of the bottom
+	Section   string    // Insert into the section under this heading
+	Timestamp time.Time // Put a heading with this time above the content
+}
+
+// InsertContent returns body with content added at the end, or at the
+// top just below the title when prepending. With a section, content is
+// added at the end or top of the section under that heading, which is
+// created at the end of body if missing. Blocks are separated by blank
+// lines.
+func InsertContent(body, content string, opts InsertOptions) string {
+	lines := strings.Split(strings.TrimRight(body, "\n"), "\n")
+	if body == "" {
+		lines = nil
+	}
+
+	level := 1
+	pos := len(lines)
+	if opts.Prepend {
+		pos = bodyStart(lines)
+	}
+
+	if opts.Section != "" {
+		section := findSection(ParseHeadings(body), opts.Section)
+		if section == nil {
+			lines = append(lines, "", "## "+opts.Section)
+			level, pos = 2, len(lines)
+		} else {
+			level = section.Level
+			pos = sectionEnd(ParseHeadings(body), section, len(lines))
+			if opts.Prepend {
+				pos = section.Line
+			}
+		}
+	}
+
+	var block []string
+	if !opts.Timestamp.IsZero() {
+		block = append(block, strings.Repeat("#", min(level+1, 6))+" "+opts.Timestamp.Format("2006-01-02 15:04"), "")
+	}
+	block = append(block, strings.Split(strings.Trim(content, "\n"), "\n")...)
+
+	before := trimBlankLines(lines[:pos], false)
+	after := trimBlankLines(lines[pos:], true)
+
+	var result []string
+	result = append(result, before...)
+	if len(before) > 0 {
+		result = append(result, "")
+	}
+	result = append(result, block...)
+	if len(after) > 0 {
+		result = append(result, "")
+	}
+	result = append(result, after...)
+
+	return strings.Join(result, "\n") + "\n"
+}
+
+// bodyStart returns the index of the first line after any frontmatter
+// and leading H1 title.
+func bodyStart(lines []string) int {
+	start := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	for i := start; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "# ") {
+			return i + 1
+		}
+		break
+	}
+	return start
+}
+
+func findSection(headings []Heading, name string) *Heading {
+	for i := range headings {
+		if strings.EqualFold(headings[i].Text, strings.TrimSpace(name)) {
+			return &headings[i]
+		}
+	}
+	return nil
+}
+
+// sectionEnd returns the index of the line where the next heading of
+// the same or a higher level starts, or total.
+func sectionEnd(headings []Heading, section *Heading, total int) int {
+	for _, heading := range headings {
+		if heading.Line > section.Line && heading.Level <= section.Level {
+			return heading.Line - 1
+		}
+	}
+	return total
+}
+
+func trimBlankLines(lines []string, leading bool) []string {
+	if leading {
+		for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+			lines = lines[1:]
+		}
+		return lines
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,200 @@
+package zet_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestParseHeadings(t *testing.T) {
+	body := "# Title\n\n## First #\ntext\n```\n## Not a heading\n```\n#hashtag\n### Second"
+	expected := []zet.Heading{
+		{Level: 1, Text: "Title", Line: 1},
+		{Level: 2, Text: "First", Line: 3},
+		{Level: 3, Text: "Second", Line: 9},
+	}
+
+	result := zet.ParseHeadings(body)
+	if len(result) != len(expected) {
+		t.Fatalf("ParseHeadings() = %+v, want %+v", result, expected)
+	}
+	for i := range result {
+		if result[i] != expected[i] {
+			t.Errorf("ParseHeadings()[%d] = %+v, want %+v", i, result[i], expected[i])
+		}
+	}
+}
+
+func TestSectionContent(t *testing.T) {
+	body := "# Title\n## A\na\n### A.1\nsub\n## B\nb"
+
+	section, ok := zet.SectionContent(body, "a")
+	if !ok || section != "## A\na\n### A.1\nsub" {
+		t.Errorf("SectionContent() = %q, %v", section, ok)
+	}
+
+	section, ok = zet.SectionContent(body, "B")
+	if !ok || section != "## B\nb" {
+		t.Errorf("SectionContent() of last section = %q, %v", section, ok)
+	}
+
+	_, ok = zet.SectionContent(body, "C")
+	if ok {
+		t.Error("SectionContent() of a missing heading should not be found")
+	}
+}
+
+func TestParseBlocks(t *testing.T) {
+	body := "para one\ncontinues ^first\n\n- item ^item-2\n`code ^no`\nemail^no"
+	result := zet.ParseBlocks(body)
+
+	expected := []zet.Block{{ID: "first", Line: 2}, {ID: "item-2", Line: 4}}
+	if len(result) != len(expected) {
+		t.Fatalf("ParseBlocks() = %+v, want %+v", result, expected)
+	}
+	for i := range result {
+		if result[i] != expected[i] {
+			t.Errorf("ParseBlocks()[%d] = %+v, want %+v", i, result[i], expected[i])
+		}
+	}
+
+	block, ok := zet.BlockContent(body, "first")
+	if !ok || block != "para one\ncontinues" {
+		t.Errorf("BlockContent() = %q, %v", block, ok)
+	}
+}
+
+func TestAnchorLine(t *testing.T) {
+	body := "# Title\n\n## Details\ntext ^b1\n"
+
+	tests := []struct {
+		name     string
+		link     zet.Link
+		line     int
+		expected bool
+	}{
+		{name: "no anchor", link: zet.Link{Target: "T"}, line: 1, expected: true},
+		{name: "heading", link: zet.Link{Heading: "details"}, line: 3, expected: true},
+		{name: "block", link: zet.Link{Block: "b1"}, line: 4, expected: true},
+		{name: "missing heading", link: zet.Link{Heading: "Nope"}, expected: false},
+		{name: "missing block", link: zet.Link{Block: "nope"}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, ok := zet.AnchorLine(body, tt.link)
+			if line != tt.line || ok != tt.expected {
+				t.Errorf("AnchorLine() = %d, %v, want %d, %v", line, ok, tt.line, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHeadingRenames(t *testing.T) {
+	before := "# T\n## Intro\n## Method\n## Results"
+	after := "# T\n## Introduction\n## Method\n## Findings"
+
+	result := zet.HeadingRenames(before, after)
+	if len(result) != 2 || result["Intro"] != "Introduction" || result["Results"] != "Findings" {
+		t.Errorf("HeadingRenames() = %v", result)
+	}
+
+	if result := zet.HeadingRenames(before, before+"\n## More"); len(result) != 0 {
+		t.Errorf("HeadingRenames() with added heading = %v, want none", result)
+	}
+}
+
+func TestInsertContent(t *testing.T) {
+	stamp := time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)
+	body := "# Inbox\n\nold\n\n## Ideas\n\nfirst idea\n\n## Later\n\nsomeday\n"
+
+	tests := []struct {
+		name     string
+		body     string
+		opts     zet.InsertOptions
+		expected string
+	}{
+		{
+			name:     "append to empty note",
+			body:     "",
+			expected: "new\n",
+		},
+		{
+			name:     "append",
+			body:     "# Inbox\n\nold\n",
+			expected: "# Inbox\n\nold\n\nnew\n",
+		},
+		{
+			name:     "prepend below title",
+			body:     "# Inbox\n\nold\n",
+			opts:     zet.InsertOptions{Prepend: true},
+			expected: "# Inbox\n\nnew\n\nold\n",
+		},
+		{
+			name:     "append to section",
+			body:     body,
+			opts:     zet.InsertOptions{Section: "ideas"},
+			expected: "# Inbox\n\nold\n\n## Ideas\n\nfirst idea\n\nnew\n\n## Later\n\nsomeday\n",
+		},
+		{
+			name:     "prepend to section",
+			body:     body,
+			opts:     zet.InsertOptions{Prepend: true, Section: "Later"},
+			expected: "# Inbox\n\nold\n\n## Ideas\n\nfirst idea\n\n## Later\n\nnew\n\nsomeday\n",
+		},
+		{
+			name:     "missing section is created",
+			body:     "# Inbox\n",
+			opts:     zet.InsertOptions{Section: "Links"},
+			expected: "# Inbox\n\n## Links\n\nnew\n",
+		},
+		{
+			name:     "timestamp header",
+			body:     "# Inbox\n",
+			opts:     zet.InsertOptions{Timestamp: stamp},
+			expected: "# Inbox\n\n## 2026-10-19 09:30\n\nnew\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := zet.InsertContent(tt.body, "new\n", tt.opts)
+			if result != tt.expected {
+				t.Errorf("InsertContent() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAddToNote(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	originalZetDir := os.Getenv("ZETDIR")
+	os.Setenv("ZETDIR", zetDir)
+	defer func() {
+		if originalZetDir != "" {
+			os.Setenv("ZETDIR", originalZetDir)
+		} else {
+			os.Unsetenv("ZETDIR")
+		}
+	}()
+
+	for _, content := range []string{"first", "second"} {
+		_, err := zet.AddToNote("Inbox", content, zet.InsertOptions{})
+		if err != nil {
+			t.Fatalf("AddToNote() error = %v", err)
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Join(zetDir, "Inbox.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "# Inbox\n\nfirst\n\nsecond\n" {
+		t.Errorf("note content = %q", string(content))
+	}
+}
@@ -0,0 +1,174 @@
+package zet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Mention is an occurrence of a note's title or alias in the prose of
+// another note that is not already a link.
+type Mention struct {
+	Note  *Note  // The note containing the mention
+	Text  string // The mention as written
+	Line  int    // 1-based line number of the mention
+	Start int    // Byte offset of the mention in the body
+	End   int    // Byte offset just past the mention in the body
+}
+
+var anyLinkRe = regexp.MustCompile(`!?\[[^\[\]\n]*\]\([^()\n]*\)|<?[a-z][a-z0-9+.-]*://\S+`)
+
+// FindMentions returns the unlinked mentions of the title and aliases
+// of target in notes. Matches are case-insensitive on whole words and
+// skip frontmatter, headings, code and existing links.
+func FindMentions(notes []*Note, target *Note) []Mention {
+	var patterns []*regexp.Regexp
+	for _, name := range append([]string{target.Title}, NoteAliases(target)...) {
+		if strings.TrimSpace(name) != "" {
+			patterns = append(patterns, regexp.MustCompile(`(?i)`+regexp.QuoteMeta(name)))
+		}
+	}
+
+	var mentions []Mention
+	for _, note := range notes {
+		if note.Path == target.Path {
+			continue
+		}
+		mentions = append(mentions, noteMentions(note, patterns)...)
+	}
+	return mentions
+}
+
+func noteMentions(note *Note, patterns []*regexp.Regexp) []Mention {
+	front, _ := SplitFrontmatter(note.Body)
+	masked := maskLinks(note.Body, CodeMaskedLines(note.Body))
+	headings := make(map[int]bool)
+	for _, heading := range ParseHeadings(note.Body) {
+		headings[heading.Line] = true
+	}
+
+	var found []Mention
+	offset := 0
+	for i, line := range masked {
+		if offset >= len(front) && !headings[i+1] {
+			for _, re := range patterns {
+				for _, m := range re.FindAllStringIndex(line, -1) {
+					if !wordBoundary(line, m[0], m[1]) {
+						continue
+					}
+					found = append(found, Mention{
+						Note:  note,
+						Text:  note.Body[offset+m[0] : offset+m[1]],
+						Line:  i + 1,
+						Start: offset + m[0],
+						End:   offset + m[1],
+					})
+				}
+			}
+		}
+		offset += len(line) + 1
+	}
+
+	// Prefer the longest of overlapping matches, such as an alias that
+	// contains the title
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Start != found[j].Start {
+			return found[i].Start < found[j].Start
+		}
+		return found[i].End > found[j].End
+	})
+	var mentions []Mention
+	for _, m := range found {
+		if len(mentions) > 0 && m.Start < mentions[len(mentions)-1].End {
+			continue
+		}
+		mentions = append(mentions, m)
+	}
+	return mentions
+}
+
+// maskLinks blanks the links of body in its masked lines so that their
+// text is not reported as a mention.
+func maskLinks(body string, lines []string) []string {
+	var spans [][2]int
+	for _, link := range ParseLinks(body) {
+		spans = append(spans, [2]int{link.Start, link.End})
+	}
+
+	offset := 0
+	for i, line := range lines {
+		b := []byte(line)
+		for _, span := range spans {
+			for j := max(span[0], offset); j < min(span[1], offset+len(line)); j++ {
+				b[j-offset] = ' '
+			}
+		}
+		for _, m := range anyLinkRe.FindAllIndex(b, -1) {
+			for j := m[0]; j < m[1]; j++ {
+				b[j] = ' '
+			}
+		}
+		lines[i] = string(b)
+		offset += len(line) + 1
+	}
+	return lines
+}
+
+func wordBoundary(line string, start, end int) bool {
+	isWord := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+	}
+	if before, _ := utf8.DecodeLastRuneInString(line[:start]); start > 0 && isWord(before) {
+		return false
+	}
+	if after, _ := utf8.DecodeRuneInString(line[end:]); end < len(line) && isWord(after) {
+		return false
+	}
+	return true
+}
+
+// LinkMentions returns body with each of mentions, which must all be
+// in body, replaced by a wikilink to target that keeps the mention as
+// written.
+func LinkMentions(body string, mentions []Mention, target *Note) string {
+	sorted := append([]Mention{}, mentions...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start > sorted[j].Start
+	})
+
+	for _, m := range sorted {
+		link := Link{Target: target.Title}
+		if m.Text != target.Title {
+			link.Label = m.Text
+		}
+		body = body[:m.Start] + FormatWikiLink(link) + body[m.End:]
+	}
+	return body
+}
+
+// WriteMentionLinks links mentions, all found in note, to target and
+// saves note, unless the file has changed since note was read.
+func WriteMentionLinks(note *Note, mentions []Mention, target *Note) error {
+	current, err := os.ReadFile(note.Path)
+	if err != nil {
+		return err
+	}
+	if string(current) != note.Body {
+		return fmt.Errorf("%s changed since it was searched", note.Title)
+	}
+
+	body := LinkMentions(note.Body, mentions, target)
+	err = AtomicWriteFile(note.Path, []byte(body), 0644)
+	if err != nil {
+		return err
+	}
+
+	RefreshDaemon(filepath.Dir(note.Path), note.Path)
+	fireHook(HookEdited, note.Path, note.Title, "")
+	return nil
+}
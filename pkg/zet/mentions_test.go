@@ -0,0 +1,81 @@
+package zet_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestFindMentions(t *testing.T) {
+	target := &zet.Note{Title: "Go", Path: "/tmp/Go.md", Body: "---\naliases: [Golang]\n---\n# Go\n"}
+
+	tests := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{name: "plain mention", body: "I like go a lot.", expected: []string{"1:go"}},
+		{name: "alias", body: "Golang and Go", expected: []string{"1:Golang", "1:Go"}},
+		{name: "word boundaries", body: "Going to Gopher go_x ago", expected: nil},
+		{name: "unicode boundary", body: "Goé Go.", expected: []string{"1:Go"}},
+		{name: "already linked", body: "[[Go]] [Go](Go.md) [[Other|Go]] ![[Go]]", expected: nil},
+		{name: "code", body: "`Go`\n```\nGo\n```\nGo", expected: []string{"5:Go"}},
+		{name: "headings and frontmatter", body: "---\ntopic: Go\n---\n# About Go\n## Go\nGo", expected: []string{"6:Go"}},
+		{name: "urls", body: "https://go.dev/Go [site](https://go.dev)", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			note := &zet.Note{Title: "Other", Path: "/tmp/Other.md", Body: tt.body}
+			var got []string
+			for _, m := range zet.FindMentions([]*zet.Note{target, note}, target) {
+				got = append(got, string(rune('0'+m.Line))+":"+m.Text)
+				if tt.body[m.Start:m.End] != m.Text {
+					t.Errorf("mention offsets %d:%d do not match %q", m.Start, m.End, m.Text)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("FindMentions() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteMentionLinks(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	target := &zet.Note{Title: "Go", Path: filepath.Join(zetDir, "Go.md"), Body: "# Go\n"}
+	path := filepath.Join(zetDir, "Other.md")
+	err := os.WriteFile(path, []byte("# Other\ngo and Go"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	note, err := zet.ReadNote(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mentions := zet.FindMentions([]*zet.Note{note}, target)
+	err = zet.WriteMentionLinks(note, mentions, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "# Other\n[[Go|go]] and [[Go]]"
+	if string(content) != expected {
+		t.Errorf("WriteMentionLinks() wrote %q, want %q", content, expected)
+	}
+
+	err = zet.WriteMentionLinks(note, mentions, target)
+	if err == nil {
+		t.Error("WriteMentionLinks() on a changed note should fail")
+	}
+}
@@ -0,0 +1,140 @@
+package zet
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// FileChange is a planned rewrite of a note file. After is empty when
+// the file is to be deleted.
+type FileChange struct {
+	Path   string
+	Before string
+	After  string
+}
+
+// PlanMerge returns the changes that merge source into target: source
+// is appended to target under a heading with its title, their
+// frontmatter is combined, links to source anywhere in notes are
+// pointed at target and source is deleted.
+func PlanMerge(notes []*Note, source, target *Note) []FileChange {
+	r := newResolver(notes)
+	toSource := func(link Link) bool {
+		if link.Target == "" {
+			return false
+		}
+		linked := r.lookup(link.Target)
+		return linked != nil && linked.Path == source.Path
+	}
+
+	merged := RewriteLinks(MergeBodies(source, target), toSource, target)
+	changes := []FileChange{{Path: target.Path, Before: target.Body, After: merged}}
+	for _, note := range notes {
+		if note.Path == source.Path || note.Path == target.Path {
+			continue
+		}
+		if body := RewriteLinks(note.Body, toSource, target); body != note.Body {
+			changes = append(changes, FileChange{Path: note.Path, Before: note.Body, After: body})
+		}
+	}
+	return append(changes, FileChange{Path: source.Path, Before: source.Body})
+}
+
+// MergeBodies returns the body of target with the body of source, less
+// its frontmatter and title, appended under a heading with the title of
+// source. Headings of source are demoted a level to nest under it and
+// the frontmatter of both is combined.
+func MergeBodies(source, target *Note) string {
+	lines := strings.Split(source.Body, "\n")
+	start := bodyStart(lines)
+	for _, heading := range ParseHeadings(source.Body) {
+		if heading.Line > start && heading.Level < 6 {
+			lines[heading.Line-1] = "#" + lines[heading.Line-1]
+		}
+	}
+	content := strings.Join(lines[start:], "\n")
+
+	body := target.Body
+	if fm := ParseFrontmatter(source.Body); len(fm) > 0 {
+		_, rest := SplitFrontmatter(target.Body)
+		body = MergeFrontmatter(ParseFrontmatter(target.Body), fm).String() + rest
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return body
+	}
+	return InsertContent(body, content, InsertOptions{Section: source.Title})
+}
+
+// MergeFrontmatter returns target with the fields of source added.
+// Tags, aliases and list fields present in both are combined; for other
+// fields present in both, target wins.
+func MergeFrontmatter(target, source Frontmatter) Frontmatter {
+	merged := target
+	for _, field := range source {
+		existing, ok := merged.Field(field.Key)
+		if !ok {
+			merged = merged.Set(field)
+			continue
+		}
+
+		key := strings.ToLower(field.Key)
+		if !field.List && !existing.List && key != "tags" && key != "aliases" {
+			continue
+		}
+
+		union := Field{Key: existing.Key, List: true, Values: append([]string{}, existing.Values...)}
+		for _, value := range field.Values {
+			if !slices.ContainsFunc(union.Values, func(v string) bool { return strings.EqualFold(v, value) }) {
+				union.Values = append(union.Values, value)
+			}
+		}
+		merged = merged.Set(union)
+	}
+	return merged
+}
+
+// MergeNotes applies the changes of PlanMerge, unless the pre-delete
+// hook of source fails. A pin on source and its usage history move to
+// target.
+func MergeNotes(notes []*Note, source, target *Note) error {
+	err := RunHook(HookPreDelete, source.Path, source.Title, "")
+	if err != nil {
+		return err
+	}
+
+	var paths []string
+	for _, change := range PlanMerge(notes, source, target) {
+		paths = append(paths, change.Path)
+		if change.After == "" {
+			err = os.Remove(change.Path)
+			if err != nil {
+				return err
+			}
+			fireHook(HookDeleted, source.Path, source.Title, "")
+			continue
+		}
+
+		err = AtomicWriteFile(change.Path, []byte(change.After), 0644)
+		if err != nil {
+			return err
+		}
+		if note, err := ReadNote(change.Path); err == nil {
+			fireHook(HookEdited, note.Path, note.Title, "")
+		}
+	}
+
+	err = renamePin(source.Path, target.Path)
+	if err != nil {
+		return err
+	}
+	err = renameUsage(source.Path, target.Path)
+	if err != nil {
+		return err
+	}
+
+	RefreshDaemon(filepath.Dir(target.Path), paths...)
+	return nil
+}
@@ -0,0 +1,137 @@
+package zet_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestMergeBodies(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		target   string
+		expected string
+	}{
+		{
+			name:     "appended under heading",
+			source:   "# Source\n\nSource text\n\n## Detail\n\nMore",
+			target:   "# Target\n\nTarget text\n",
+			expected: "# Target\n\nTarget text\n\n## Source\n\nSource text\n\n### Detail\n\nMore\n",
+		},
+		{
+			name:     "frontmatter combined",
+			source:   "---\ntags: [b, a]\nstatus: done\nauthor: Sam\n---\n# Source\nText",
+			target:   "---\ntags: [a]\nstatus: draft\n---\n# Target\n",
+			expected: "---\ntags: [a, b]\nstatus: draft\nauthor: Sam\n---\n# Target\n\n## Source\n\nText\n",
+		},
+		{
+			name:     "empty source",
+			source:   "# Source\n",
+			target:   "# Target\n",
+			expected: "# Target\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &zet.Note{Title: "Source", Path: "/tmp/Source.md", Body: tt.source}
+			target := &zet.Note{Title: "Target", Path: "/tmp/Target.md", Body: tt.target}
+			if got := zet.MergeBodies(source, target); got != tt.expected {
+				t.Errorf("MergeBodies() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeNotes(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	files := map[string]string{
+		"Source.md": "# Source\n\nSee [[Source#Part]].\n\n## Part\n",
+		"Target.md": "# Target\n\nAbout [[Source]].\n",
+		"Other.md":  "# Other\n\n[[Source|src]] and [s](Source.md) and [[Target]]\n",
+		"Plain.md":  "# Plain\n",
+	}
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(zetDir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var notes []*zet.Note
+	for _, name := range []string{"Other.md", "Plain.md", "Source.md", "Target.md"} {
+		note, err := zet.ReadNote(filepath.Join(zetDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		notes = append(notes, note)
+	}
+	// Load source and target apart from the vault, as zet merge does
+	source, err := zet.ReadNote(filepath.Join(zetDir, "Source.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := zet.ReadNote(filepath.Join(zetDir, "Target.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, note := range []*zet.Note{source, notes[1]} {
+		if err := zet.PinNote(note); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zet.RecordUsage(source.Path, zet.UsageEdited); err != nil {
+		t.Fatal(err)
+	}
+
+	changes := zet.PlanMerge(notes, source, target)
+	if len(changes) != 3 {
+		t.Fatalf("PlanMerge() = %d changes, want target, Other and source", len(changes))
+	}
+
+	err = zet.MergeNotes(notes, source, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"Target.md": "# Target\n\nAbout [[Target]].\n\n## Source\n\nSee [[Target#Part]].\n\n### Part\n",
+		"Other.md":  "# Other\n\n[[Target|src]] and [s](Target.md) and [[Target]]\n",
+		"Plain.md":  "# Plain\n",
+	}
+	for name, want := range expected {
+		content, err := os.ReadFile(filepath.Join(zetDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != want {
+			t.Errorf("%s after merge = %q, want %q", name, content, want)
+		}
+	}
+	if _, err := os.Stat(source.Path); err == nil {
+		t.Error("source should be deleted after merge")
+	}
+
+	// The pin and usage of source now belong to target
+	pins, err := zet.ReadPins(zetDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(pins, ",") != "Target.md,Plain.md" {
+		t.Errorf("pins after merge = %v, want [Target.md Plain.md]", pins)
+	}
+	usage, err := zet.ReadUsage(zetDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usage) != 1 || usage[0].Path != target.Path {
+		t.Errorf("usage after merge = %v, want the edit of source on %s", usage, target.Path)
+	}
+}
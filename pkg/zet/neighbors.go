@@ -0,0 +1,66 @@
+package zet
+
+import "strings"
+
+// NeighborTree returns an ASCII tree of the notes within depth links of
+// the node at root. Notes root links to are marked "→" and notes linking
+// to it "←". Each note appears once, under the first note found at the
+// smallest depth from root.
+func (g *Graph) NeighborTree(root, depth int) string {
+	type child struct {
+		node     int
+		incoming bool
+	}
+
+	var outgoing, incoming [][]int
+	outgoing = make([][]int, len(g.Nodes))
+	incoming = make([][]int, len(g.Nodes))
+	for _, edge := range g.Edges {
+		outgoing[edge.From] = append(outgoing[edge.From], edge.To)
+		incoming[edge.To] = append(incoming[edge.To], edge.From)
+	}
+
+	children := make(map[int][]child)
+	placed := map[int]bool{root: true}
+	level := []int{root}
+	for d := 0; d < depth && len(level) > 0; d++ {
+		var next []int
+		for _, node := range level {
+			for _, to := range outgoing[node] {
+				if !placed[to] {
+					placed[to] = true
+					children[node] = append(children[node], child{to, false})
+					next = append(next, to)
+				}
+			}
+			for _, from := range incoming[node] {
+				if !placed[from] {
+					placed[from] = true
+					children[node] = append(children[node], child{from, true})
+					next = append(next, from)
+				}
+			}
+		}
+		level = next
+	}
+
+	var b strings.Builder
+	b.WriteString(g.Nodes[root].Title + "\n")
+	var write func(node int, prefix string)
+	write = func(node int, prefix string) {
+		for i, c := range children[node] {
+			branch, indent := "├── ", "│   "
+			if i == len(children[node])-1 {
+				branch, indent = "└── ", "    "
+			}
+			arrow := "→ "
+			if c.incoming {
+				arrow = "← "
+			}
+			b.WriteString(prefix + branch + arrow + g.Nodes[c.node].Title + "\n")
+			write(c.node, prefix+indent)
+		}
+	}
+	write(root, "")
+	return b.String()
+}
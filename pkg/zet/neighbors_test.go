@@ -0,0 +1,28 @@
+package zet_test
+
+import (
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestNeighborTree(t *testing.T) {
+	g := zet.BuildGraph(linkedNotes(
+		[]string{"Root", "Out", "Deep", "In", "Far"},
+		map[string][]string{"Root": {"Out"}, "Out": {"Deep", "Root"}, "In": {"Root"}, "Far": {"In"}},
+	))
+
+	tests := []struct {
+		depth    int
+		expected string
+	}{
+		{0, "Root\n"},
+		{1, "Root\n├── → Out\n└── ← In\n"},
+		{2, "Root\n├── → Out\n│   └── → Deep\n└── ← In\n    └── ← Far\n"},
+	}
+	for _, tt := range tests {
+		if got := g.NeighborTree(0, tt.depth); got != tt.expected {
+			t.Errorf("NeighborTree(depth %d) =\n%s\nwant\n%s", tt.depth, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package zet
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// PinMarker is shown before pinned notes in fzf.
+const PinMarker = "📌"
+
+func GetPinsPath(dir string) string {
+	return filepath.Join(GetVaultConfigDir(dir), "pins")
+}
+
+// ReadPins returns the filenames of the notes pinned in the vault in
+// dir, in the order they were pinned.
+func ReadPins(dir string) ([]string, error) {
+	file, err := os.Open(GetPinsPath(dir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var pins []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			pins = append(pins, line)
+		}
+	}
+	return pins, scanner.Err()
+}
+
+func writePins(dir string, pins []string) error {
+	err := os.MkdirAll(GetVaultConfigDir(dir), 0755)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	for _, pin := range pins {
+		b.WriteString(pin + "\n")
+	}
+	return AtomicWriteFile(GetPinsPath(dir), []byte(b.String()), 0644)
+}
+
+// PinNote adds note to the end of the pinned notes of its vault.
+func PinNote(note *Note) error {
+	dir := filepath.Dir(note.Path)
+	pins, err := ReadPins(dir)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(note.Path)
+	if slices.Contains(pins, name) {
+		return fmt.Errorf("%s is already pinned", note.Title)
+	}
+	return writePins(dir, append(pins, name))
+}
+
+// UnpinNote removes note from the pinned notes of its vault.
+func UnpinNote(note *Note) error {
+	dir := filepath.Dir(note.Path)
+	pins, err := ReadPins(dir)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(note.Path)
+	if !slices.Contains(pins, name) {
+		return fmt.Errorf("%s is not pinned", note.Title)
+	}
+	return writePins(dir, slices.DeleteFunc(pins, func(pin string) bool { return pin == name }))
+}
+
+// renamePin keeps a pin on the note at oldPath when it moves to path,
+// or is merged into it, in which case a pin path already has is kept.
+func renamePin(oldPath, path string) error {
+	dir := filepath.Dir(oldPath)
+	pins, err := ReadPins(dir)
+	if err != nil {
+		return err
+	}
+
+	i := slices.Index(pins, filepath.Base(oldPath))
+	if i < 0 {
+		return nil
+	}
+	if slices.Contains(pins, filepath.Base(path)) {
+		return writePins(dir, slices.Delete(pins, i, i+1))
+	}
+	pins[i] = filepath.Base(path)
+	return writePins(dir, pins)
+}
+
+// PinnedNotes returns the notes pinned in the vault in dir, in pin
+// order. Pins of notes that no longer exist are skipped.
+func PinnedNotes(dir string, notes []*Note) ([]*Note, error) {
+	pins, err := ReadPins(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*Note)
+	for _, note := range notes {
+		byName[filepath.Base(note.Path)] = note
+	}
+
+	var pinned []*Note
+	for _, pin := range pins {
+		if note := byName[pin]; note != nil && !slices.Contains(pinned, note) {
+			pinned = append(pinned, note)
+		}
+	}
+	return pinned, nil
+}
+
+// PinFirst returns notes with those pinned in the vault in dir moved to
+// the top in pin order, and how many of them are pinned.
+func PinFirst(dir string, notes []*Note) ([]*Note, int) {
+	pinned, err := PinnedNotes(dir, notes)
+	if err != nil || len(pinned) == 0 {
+		return notes, 0
+	}
+
+	sorted := append([]*Note{}, pinned...)
+	for _, note := range notes {
+		if !slices.Contains(pinned, note) {
+			sorted = append(sorted, note)
+		}
+	}
+	return sorted, len(pinned)
+}
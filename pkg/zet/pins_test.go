@@ -0,0 +1,103 @@
+package zet_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestPinNote(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	var notes []*zet.Note
+	for _, title := range []string{"Alpha", "Beta", "Gamma"} {
+		notes = append(notes, &zet.Note{Title: title, Path: filepath.Join(zetDir, title+".md")})
+	}
+
+	titles := func(notes []*zet.Note) string {
+		var names []string
+		for _, note := range notes {
+			names = append(names, note.Title)
+		}
+		return strings.Join(names, ",")
+	}
+
+	for _, note := range []*zet.Note{notes[2], notes[0]} {
+		if err := zet.PinNote(note); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zet.PinNote(notes[0]); err == nil {
+		t.Error("PinNote() of a pinned note should fail")
+	}
+
+	content, err := os.ReadFile(zet.GetPinsPath(zetDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "Gamma.md\nAlpha.md\n" {
+		t.Errorf("pins file = %q, want %q", content, "Gamma.md\nAlpha.md\n")
+	}
+
+	pinned, err := zet.PinnedNotes(zetDir, notes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := titles(pinned); got != "Gamma,Alpha" {
+		t.Errorf("PinnedNotes() = %s, want Gamma,Alpha", got)
+	}
+	if pinned, err := zet.PinnedNotes(zetDir, notes[1:]); err != nil || titles(pinned) != "Gamma" {
+		t.Errorf("PinnedNotes() of a missing note = %s, %v, want Gamma", titles(pinned), err)
+	}
+
+	sorted, count := zet.PinFirst(zetDir, notes)
+	if got := titles(sorted); got != "Gamma,Alpha,Beta" || count != 2 {
+		t.Errorf("PinFirst() = %s, %d, want Gamma,Alpha,Beta, 2", got, count)
+	}
+
+	if err := zet.UnpinNote(notes[2]); err != nil {
+		t.Fatal(err)
+	}
+	if err := zet.UnpinNote(notes[1]); err == nil {
+		t.Error("UnpinNote() of a note that is not pinned should fail")
+	}
+	sorted, count = zet.PinFirst(zetDir, notes)
+	if got := titles(sorted); got != "Alpha,Beta,Gamma" || count != 1 {
+		t.Errorf("PinFirst() after unpinning = %s, %d, want Alpha,Beta,Gamma, 1", got, count)
+	}
+}
+
+func TestRenameNoteKeepsPin(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	path := filepath.Join(zetDir, "Draft.md")
+	err := os.WriteFile(path, []byte("# Draft\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	note, err := zet.ReadNote(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := zet.PinNote(note); err != nil {
+		t.Fatal(err)
+	}
+
+	renamed, err := zet.RenameNote(note, "Final")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pinned, err := zet.PinnedNotes(zetDir, []*zet.Note{renamed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pinned) != 1 || pinned[0] != renamed {
+		t.Errorf("PinnedNotes() after rename = %v, want the renamed note", pinned)
+	}
+}
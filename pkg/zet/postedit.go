@@ -0,0 +1,127 @@
+package zet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	StepDeleteEmpty = "delete-empty"
+	StepRename      = "rename"
+	StepHeadings    = "headings"
+	StepIndex       = "index"
+	StepHooks       = "hooks"
+)
+
+// EditAndFinish edits the note at path, starting at line and col when
+// line is positive, fires the edited hook if it changed, then runs the
+// post-edit steps on it.
+func EditAndFinish(path string, line, col int) error {
+	before, err := ReadNote(path)
+	if err != nil {
+		return err
+	}
+
+	changed, err := EditNoteAt(path, line, col)
+	if err != nil {
+		return err
+	}
+	if changed {
+		if note, err := ReadNote(path); err == nil {
+			fireHook(HookEdited, path, note.Title, "")
+		}
+	}
+
+	return PostEdit(before)
+}
+
+// PostEdit runs the configured post-edit steps on the note that looked
+// like before when the editor was opened:
+//
+//   - delete-empty removes the note if it still holds nothing but its title
+//   - rename offers to rename the file when the H1 title changed
+//   - headings offers to update links to headings that were renamed
+//   - index refreshes the daemon's index of the note
+//   - hooks fires the post-edit hook
+func PostEdit(before *Note) error {
+	dir := filepath.Dir(before.Path)
+
+	note, err := ReadNote(before.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, step := range GetPostEditSteps(dir) {
+		switch step {
+		case StepDeleteEmpty:
+			if IsEmptyNote(before.Body) && IsEmptyNote(note.Body) {
+				err = DeleteNote(note)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Deleted empty note %s\n", note.Title)
+				RefreshDaemon(dir, note.Path)
+				return nil
+			}
+
+		case StepRename:
+			if ParseTitle(note.Body) == ParseTitle(before.Body) || ParseTitle(note.Body) == "" {
+				continue
+			}
+			filename, err := NoteFilename(dir, note.Title)
+			if err != nil || filename == filepath.Base(note.Path) {
+				continue
+			}
+			ok, err := Confirm(fmt.Sprintf("Title changed, rename %s to %s?", filepath.Base(note.Path), filename))
+			if err != nil || !ok {
+				continue
+			}
+			oldPath := note.Path
+			note, err = RenameNote(note, note.Title)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Renamed %s @ %s\n", note.Title, note.Path)
+			RefreshDaemon(dir, oldPath, note.Path)
+
+		case StepHeadings:
+			for oldHeading, newHeading := range HeadingRenames(before.Body, note.Body) {
+				question := fmt.Sprintf("Heading %q renamed to %q, update links to it?", oldHeading, newHeading)
+				ok, err := Confirm(question)
+				if err != nil || !ok {
+					continue
+				}
+				changed, err := RenameHeadingLinks(note, oldHeading, newHeading)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Updated links in %d notes\n", changed)
+			}
+
+		case StepIndex:
+			RefreshDaemon(dir, note.Path)
+
+		case StepHooks:
+			fireHook(HookPostEdit, note.Path, note.Title, "")
+
+		default:
+			return fmt.Errorf("unknown post-edit step: %s", step)
+		}
+	}
+
+	return nil
+}
+
+// IsEmptyNote reports whether body holds nothing besides an H1 title.
+func IsEmptyNote(body string) bool {
+	body = strings.TrimSpace(body)
+	if title := ParseTitle(body); title != "" {
+		body = strings.TrimSpace(strings.TrimPrefix(body, "# "+title))
+	}
+	return body == ""
+}
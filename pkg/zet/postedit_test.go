@@ -0,0 +1,189 @@
+package zet_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestIsEmptyNote(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected bool
+	}{
+		{name: "empty", body: "", expected: true},
+		{name: "whitespace", body: " \n\n", expected: true},
+		{name: "title only", body: "# My Note\n\n", expected: true},
+		{name: "title and text", body: "# My Note\n\ntext", expected: false},
+		{name: "text only", body: "text", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := zet.IsEmptyNote(tt.body)
+			if result != tt.expected {
+				t.Errorf("IsEmptyNote(%q) = %v, want %v", tt.body, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEditAndFinishDeletesEmptyNote(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+	toolDir := ZetDir(t)
+	defer Cleanup(t, toolDir)
+
+	originalZetDir := os.Getenv("ZETDIR")
+	os.Setenv("ZETDIR", zetDir)
+	defer func() {
+		if originalZetDir != "" {
+			os.Setenv("ZETDIR", originalZetDir)
+		} else {
+			os.Unsetenv("ZETDIR")
+		}
+	}()
+
+	fakeEditor(t, toolDir, "true")
+
+	path, err := zet.CreateOrEditNote("Abandoned")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = zet.EditAndFinish(path, 0, 0)
+	if err != nil {
+		t.Fatalf("EditAndFinish() error = %v", err)
+	}
+
+	_, err = os.Stat(path)
+	if err == nil {
+		t.Error("empty note should be deleted after editing")
+	}
+}
+
+func TestEditAndFinishKeepsWrittenNote(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+	toolDir := ZetDir(t)
+	defer Cleanup(t, toolDir)
+
+	originalZetDir := os.Getenv("ZETDIR")
+	os.Setenv("ZETDIR", zetDir)
+	defer func() {
+		if originalZetDir != "" {
+			os.Setenv("ZETDIR", originalZetDir)
+		} else {
+			os.Unsetenv("ZETDIR")
+		}
+	}()
+
+	fakeEditor(t, toolDir, `echo content >> "$1"`)
+
+	path, err := zet.CreateOrEditNote("Written")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = zet.EditAndFinish(path, 0, 0)
+	if err != nil {
+		t.Fatalf("EditAndFinish() error = %v", err)
+	}
+
+	_, err = os.Stat(path)
+	if err != nil {
+		t.Errorf("written note should be kept: %v", err)
+	}
+}
+
+func TestEditAndFinishFiresEdited(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+	toolDir := ZetDir(t)
+	defer Cleanup(t, toolDir)
+
+	originalZetDir := os.Getenv("ZETDIR")
+	os.Setenv("ZETDIR", zetDir)
+	defer func() {
+		if originalZetDir != "" {
+			os.Setenv("ZETDIR", originalZetDir)
+		} else {
+			os.Unsetenv("ZETDIR")
+		}
+	}()
+
+	logPath := filepath.Join(toolDir, "hook.log")
+	writeHook(t, zetDir, zet.HookEdited, `echo "$ZET_NOTE_TITLE" >> `+logPath)
+
+	path, err := zet.CreateOrEditNote("Hooked")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fakeEditor(t, toolDir, `echo content >> "$1"`)
+	err = zet.EditAndFinish(path, 0, 0)
+	if err != nil {
+		t.Fatalf("EditAndFinish() error = %v", err)
+	}
+
+	// Closing the editor without changes fires nothing
+	fakeEditor(t, toolDir, "true")
+	err = zet.EditAndFinish(path, 0, 0)
+	if err != nil {
+		t.Fatalf("EditAndFinish() error = %v", err)
+	}
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("edited hook did not run: %v", err)
+	}
+	if string(content) != "Hooked\n" {
+		t.Errorf("hook log = %q, want %q", string(content), "Hooked\n")
+	}
+}
+
+func TestRenameNote(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	oldPath := filepath.Join(zetDir, "Draft.md")
+	err := os.WriteFile(oldPath, []byte("# Final Title\naliases: FT\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	linkerPath := filepath.Join(zetDir, "Linker.md")
+	err = os.WriteFile(linkerPath, []byte("see [[Draft#Intro|draft]] and [d](Draft.md) via [[FT]]"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	note, err := zet.ReadNote(oldPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	renamed, err := zet.RenameNote(note, "Final Title")
+	if err != nil {
+		t.Fatalf("RenameNote() error = %v", err)
+	}
+
+	if renamed.Path != filepath.Join(zetDir, "Final Title.md") {
+		t.Errorf("renamed.Path = %q, want %q", renamed.Path, filepath.Join(zetDir, "Final Title.md"))
+	}
+	_, err = os.Stat(oldPath)
+	if err == nil {
+		t.Error("old file should be gone after rename")
+	}
+
+	content, err := os.ReadFile(linkerPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "see [[Final Title#Intro|draft]] and [d](Final%20Title.md) via [[FT]]"
+	if string(content) != expected {
+		t.Errorf("links after rename = %q, want %q", string(content), expected)
+	}
+}
@@ -0,0 +1,50 @@
+package zet
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var stdin = bufio.NewReader(os.Stdin)
+
+// Prompt writes question to stderr and returns the trimmed line typed
+// in reply.
+func Prompt(question string) (string, error) {
+	fmt.Fprint(os.Stderr, question)
+	line, err := stdin.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// Choose asks question until the reply matches one of choices or its
+// first letter, and returns the index of the choice.
+func Choose(question string, choices ...string) (int, error) {
+	var labels []string
+	for _, choice := range choices {
+		labels = append(labels, "["+choice[:1]+"]"+choice[1:])
+	}
+
+	for {
+		reply, err := Prompt(fmt.Sprintf("%s %s: ", question, strings.Join(labels, ", ")))
+		if err != nil {
+			return -1, err
+		}
+		reply = strings.ToLower(reply)
+		for i, choice := range choices {
+			if reply == choice || reply == choice[:1] {
+				return i, nil
+			}
+		}
+	}
+}
+
+// Confirm asks a yes or no question.
+func Confirm(question string) (bool, error) {
+	choice, err := Choose(question, "yes", "no")
+	return choice == 0, err
+}
@@ -0,0 +1,638 @@
+package zet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Query is a parsed filter expression such as
+//
+//	tag:project AND modified:<7d AND links-to:"Roadmap" AND NOT title:/draft/
+//
+// Terms are combined with AND, OR, NOT (or a leading '-') and
+// parentheses, and terms side by side are ANDed. A term is a word or
+// "quoted phrase" to find in the text, or a predicate key:value:
+//
+//	text:, title:   text contained, or matching a /regex/
+//	tag:            tag, including nested tags below it
+//	path:           filename substring, glob or /regex/
+//	modified:       modification time; created: uses the created or
+//	                date frontmatter field when present
+//	links-to:       links to the note with this name
+//	linked-from:    linked from the note with this name
+//	size:, words:   size in bytes (with k or m suffix) or words
+//	any other key   frontmatter field equal to the value or a /regex/
+//
+// Dates and sizes take an operator <, <=, >, >= or =. A date is either
+// YYYY-MM-DD, today, yesterday or an age such as 7d, 2w, 3m or 1y, so
+// modified:<7d means modified less than seven days ago. An age without
+// an operator means within it, so modified:7d is modified:<7d. Text
+// matches and regular expressions ignore case.
+type Query struct {
+	Expr string
+	root queryNode
+}
+
+// QueryError reports a syntax error at a byte position in a query.
+type QueryError struct {
+	Expr string
+	Pos  int
+	Msg  string
+}
+
+func (e *QueryError) Error() string {
+	column := utf8.RuneCountInString(e.Expr[:e.Pos])
+	// The expression is indented as a block so that the caret stays
+	// under it when the error is printed as markdown
+	return fmt.Sprintf("%s at column %d\n\n    %s\n    %s^", e.Msg, column+1, e.Expr, strings.Repeat(" ", column))
+}
+
+type queryNode interface {
+	match(env *queryEnv, note *Note) bool
+}
+
+type andNode struct{ left, right queryNode }
+type orNode struct{ left, right queryNode }
+type notNode struct{ node queryNode }
+
+func (n andNode) match(env *queryEnv, note *Note) bool {
+	return n.left.match(env, note) && n.right.match(env, note)
+}
+
+func (n orNode) match(env *queryEnv, note *Note) bool {
+	return n.left.match(env, note) || n.right.match(env, note)
+}
+
+func (n notNode) match(env *queryEnv, note *Note) bool {
+	return !n.node.match(env, note)
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenLParen
+	tokenRParen
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenTerm
+)
+
+type token struct {
+	kind   tokenKind
+	pos    int
+	key    string // Predicate key, empty for plain text
+	op     string // Comparison operator, if any
+	value  string
+	regex  bool // value was written as /regex/
+	valPos int  // Position of the value
+}
+
+// ParseQuery parses expr, returning a *QueryError for syntax errors.
+func ParseQuery(expr string) (*Query, error) {
+	tokens, err := lexQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{expr: expr, tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, &QueryError{expr, 0, "empty query"}
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, &QueryError{expr, tok.pos, "unexpected " + describeToken(expr, tok)}
+	}
+	return &Query{Expr: expr, root: root}, nil
+}
+
+func lexQuery(expr string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for {
+		for i < len(expr) && (expr[i] == ' ' || expr[i] == '\t' || expr[i] == '\n') {
+			i++
+		}
+		if i >= len(expr) {
+			return append(tokens, token{kind: tokenEOF, pos: i}), nil
+		}
+
+		start := i
+		switch {
+		case expr[i] == '(':
+			tokens = append(tokens, token{kind: tokenLParen, pos: i})
+			i++
+			continue
+		case expr[i] == ')':
+			tokens = append(tokens, token{kind: tokenRParen, pos: i})
+			i++
+			continue
+		case expr[i] == '-' && i+1 < len(expr) && expr[i+1] != ' ':
+			tokens = append(tokens, token{kind: tokenNot, pos: i})
+			i++
+			continue
+		case expr[i] == '"':
+			value, end, err := lexQuoted(expr, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenTerm, pos: start, value: value, valPos: start})
+			i = end
+			continue
+		}
+
+		keyEnd := i
+		for keyEnd < len(expr) && isKeyChar(expr[keyEnd]) {
+			keyEnd++
+		}
+		if keyEnd > i && keyEnd < len(expr) && expr[keyEnd] == ':' {
+			tok := token{kind: tokenTerm, pos: start, key: strings.ToLower(expr[i:keyEnd])}
+			i = keyEnd + 1
+			for _, op := range []string{">=", "<=", ">", "<", "="} {
+				if strings.HasPrefix(expr[i:], op) {
+					tok.op = op
+					i += len(op)
+					break
+				}
+			}
+			tok.valPos = i
+
+			switch {
+			case i < len(expr) && expr[i] == '"':
+				value, end, err := lexQuoted(expr, i)
+				if err != nil {
+					return nil, err
+				}
+				tok.value, i = value, end
+			case i < len(expr) && expr[i] == '/':
+				end := i + 1
+				for end < len(expr) && expr[end] != '/' {
+					if expr[end] == '\\' {
+						end++
+					}
+					end++
+				}
+				if end >= len(expr) {
+					return nil, &QueryError{expr, i, "unterminated regular expression"}
+				}
+				tok.value, tok.regex = strings.ReplaceAll(expr[i+1:end], `\/`, "/"), true
+				i = end + 1
+			default:
+				end := i
+				for end < len(expr) && !strings.ContainsRune(" \t\n()", rune(expr[end])) {
+					end++
+				}
+				tok.value, i = expr[i:end], end
+			}
+			if tok.value == "" && !tok.regex {
+				return nil, &QueryError{expr, tok.valPos, "missing value for " + tok.key + ":"}
+			}
+			tokens = append(tokens, tok)
+			continue
+		}
+
+		end := i
+		for end < len(expr) && !strings.ContainsRune(" \t\n()", rune(expr[end])) {
+			end++
+		}
+		word := expr[i:end]
+		switch word {
+		case "AND":
+			tokens = append(tokens, token{kind: tokenAnd, pos: start})
+		case "OR":
+			tokens = append(tokens, token{kind: tokenOr, pos: start})
+		case "NOT":
+			tokens = append(tokens, token{kind: tokenNot, pos: start})
+		default:
+			tokens = append(tokens, token{kind: tokenTerm, pos: start, value: word, valPos: start})
+		}
+		i = end
+	}
+}
+
+func isKeyChar(c byte) bool {
+	return c == '-' || c == '_' || c == '.' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// lexQuoted reads the "..." string at expr[start], with backslash
+// escapes, returning it and the position just past it.
+func lexQuoted(expr string, start int) (string, int, error) {
+	var b strings.Builder
+	for i := start + 1; i < len(expr); i++ {
+		switch expr[i] {
+		case '\\':
+			if i+1 < len(expr) {
+				i++
+				b.WriteByte(expr[i])
+			}
+		case '"':
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(expr[i])
+		}
+	}
+	return "", 0, &QueryError{expr, start, "unterminated string"}
+}
+
+func describeToken(expr string, tok token) string {
+	switch tok.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenLParen:
+		return `"("`
+	case tokenRParen:
+		return `")"`
+	case tokenAnd:
+		return "AND"
+	case tokenOr:
+		return "OR"
+	case tokenNot:
+		return "NOT"
+	}
+	return fmt.Sprintf("%q", strings.Fields(expr[tok.pos:])[0])
+}
+
+type queryParser struct {
+	expr   string
+	tokens []token
+	pos    int
+}
+
+func (p *queryParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek().kind {
+		case tokenAnd:
+			p.next()
+		case tokenNot, tokenTerm, tokenLParen:
+		default:
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+}
+
+func (p *queryParser) parseNot() (queryNode, error) {
+	if p.peek().kind == tokenNot {
+		p.next()
+		node, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (queryNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokenRParen {
+			return nil, &QueryError{p.expr, tok.pos, "unclosed \"(\""}
+		}
+		return node, nil
+	case tokenTerm:
+		return newPredicate(p.expr, tok)
+	case tokenEOF:
+		return nil, &QueryError{p.expr, tok.pos, "expected a term"}
+	default:
+		return nil, &QueryError{p.expr, tok.pos, "unexpected " + describeToken(p.expr, tok)}
+	}
+}
+
+// predicate is a single key:value term.
+type predicate struct {
+	key   string
+	op    string
+	value string
+	re    *regexp.Regexp
+	date  dateValue
+	size  int64
+}
+
+type dateValue struct {
+	age     time.Duration // Set for ages such as 7d
+	daysAgo int           // Days before today, for today and yesterday
+	date    time.Time     // Set for absolute dates
+}
+
+func newPredicate(expr string, tok token) (queryNode, error) {
+	p := &predicate{key: tok.key, op: tok.op, value: tok.value}
+	if p.key == "" {
+		p.key = "text"
+	}
+	fail := func(msg string) (queryNode, error) {
+		return nil, &QueryError{expr, tok.valPos, msg}
+	}
+
+	if tok.regex {
+		re, err := regexp.Compile("(?i)" + tok.value)
+		if err != nil {
+			return fail("invalid regular expression: " + strings.TrimPrefix(err.Error(), "error parsing regexp: "))
+		}
+		p.re = re
+	}
+
+	switch p.key {
+	case "modified", "created":
+		if p.re != nil {
+			return fail(p.key + ": takes a date, not a regular expression")
+		}
+		date, err := parseDateValue(tok.value)
+		if err != nil {
+			return fail(err.Error())
+		}
+		if date.age > 0 {
+			// An age is a span of time, which nothing is exactly
+			switch p.op {
+			case "":
+				p.op = "<"
+			case "=":
+				return nil, &QueryError{expr, tok.valPos - 1, p.key + ": an age takes <, <=, > or >=, or none for within it"}
+			}
+		}
+		p.date = date
+	case "size", "words":
+		if p.re != nil {
+			return fail(p.key + ": takes a number, not a regular expression")
+		}
+		size, err := parseSizeValue(tok.value, p.key == "size")
+		if err != nil {
+			return fail(err.Error())
+		}
+		p.size = size
+	default:
+		if p.op != "" && p.op != "=" {
+			return nil, &QueryError{expr, tok.valPos - len(p.op), p.key + ": does not support " + p.op}
+		}
+	}
+	return p, nil
+}
+
+var ageRe = regexp.MustCompile(`^(\d+)([hdwmy])$`)
+
+func parseDateValue(value string) (dateValue, error) {
+	switch value {
+	case "today":
+		return dateValue{daysAgo: 0}, nil
+	case "yesterday":
+		return dateValue{daysAgo: 1}, nil
+	}
+	if m := ageRe.FindStringSubmatch(value); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		unit := map[string]time.Duration{
+			"h": time.Hour,
+			"d": 24 * time.Hour,
+			"w": 7 * 24 * time.Hour,
+			"m": 30 * 24 * time.Hour,
+			"y": 365 * 24 * time.Hour,
+		}[m[2]]
+		return dateValue{age: time.Duration(n) * unit}, nil
+	}
+	date, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return dateValue{}, fmt.Errorf("invalid date %q (want YYYY-MM-DD, today, yesterday or an age such as 7d)", value)
+	}
+	return dateValue{date: date}, nil
+}
+
+func parseSizeValue(value string, bytes bool) (int64, error) {
+	number, multiplier := strings.ToLower(value), int64(1)
+	if bytes {
+		for suffix, m := range map[string]int64{"kb": 1024, "k": 1024, "mb": 1 << 20, "m": 1 << 20, "b": 1} {
+			if trimmed, ok := strings.CutSuffix(number, suffix); ok && trimmed != "" && trimmed[len(trimmed)-1] >= '0' && trimmed[len(trimmed)-1] <= '9' {
+				number, multiplier = trimmed, m
+				break
+			}
+		}
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid number %q", value)
+	}
+	return n * multiplier, nil
+}
+
+// queryEnv holds what predicates need beyond the note itself.
+type queryEnv struct {
+	now      time.Time
+	resolver *resolver
+	links    map[string]map[string]bool // Paths linked to, by note path
+}
+
+func (env *queryEnv) linksOf(note *Note) map[string]bool {
+	if links, ok := env.links[note.Path]; ok {
+		return links
+	}
+	links := make(map[string]bool)
+	for _, link := range ParseLinks(note.Body) {
+		if target := env.resolver.lookup(link.Target); target != nil {
+			links[target.Path] = true
+		}
+	}
+	env.links[note.Path] = links
+	return links
+}
+
+func (p *predicate) match(env *queryEnv, note *Note) bool {
+	switch p.key {
+	case "text":
+		return p.matchText(note.Title + "\n" + note.Body)
+	case "title":
+		return p.matchText(note.Title)
+	case "tag":
+		if p.re != nil {
+			for _, tag := range ParseTags(note.Body) {
+				if p.re.MatchString(tag) {
+					return true
+				}
+			}
+			return false
+		}
+		return HasTag(note, p.value)
+	case "path":
+		name := filepath.Base(note.Path)
+		if p.re != nil {
+			return p.re.MatchString(name)
+		}
+		if strings.ContainsAny(p.value, "*?[") {
+			ok, _ := filepath.Match(p.value, name)
+			return ok
+		}
+		return strings.Contains(strings.ToLower(name), strings.ToLower(p.value))
+	case "modified", "created":
+		t, ok := noteTime(note, p.key == "created")
+		return ok && p.compareDate(env.now, t)
+	case "links-to":
+		if p.re != nil {
+			for _, link := range ParseLinks(note.Body) {
+				if p.re.MatchString(link.Target) {
+					return true
+				}
+			}
+			return false
+		}
+		target := env.resolver.lookup(p.value)
+		return target != nil && env.linksOf(note)[target.Path]
+	case "linked-from":
+		source := env.resolver.lookup(p.value)
+		return source != nil && env.linksOf(source)[note.Path]
+	case "size":
+		return compare(int64(len(note.Body)), p.op, p.size)
+	case "words":
+		return compare(int64(len(strings.Fields(StripFrontmatter(note.Body)))), p.op, p.size)
+	default:
+		for _, value := range ParseFrontmatter(note.Body).Get(p.key) {
+			if p.re != nil && p.re.MatchString(value) || p.re == nil && strings.EqualFold(value, p.value) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func (p *predicate) matchText(text string) bool {
+	if p.re != nil {
+		return p.re.MatchString(text)
+	}
+	return strings.Contains(strings.ToLower(text), strings.ToLower(p.value))
+}
+
+func (p *predicate) compareDate(now, t time.Time) bool {
+	switch {
+	case p.date.age > 0:
+		// Ages compare how long ago: <7d means newer than seven days
+		return compare(int64(now.Sub(t)), p.op, int64(p.date.age))
+	case p.date.date.IsZero():
+		return compareDays(t, p.op, startOfDay(now).AddDate(0, 0, -p.date.daysAgo))
+	default:
+		return compareDays(t, p.op, p.date.date)
+	}
+}
+
+func compareDays(t time.Time, op string, day time.Time) bool {
+	a, b := startOfDay(t), startOfDay(day)
+	switch op {
+	case "<":
+		return a.Before(b)
+	case "<=":
+		return !a.After(b)
+	case ">":
+		return a.After(b)
+	case ">=":
+		return !a.Before(b)
+	default:
+		return a.Equal(b)
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+func compare(a int64, op string, b int64) bool {
+	switch op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	default:
+		return a == b
+	}
+}
+
+// noteTime returns the modification time of note, or for created the
+// created or date frontmatter field when present.
+func noteTime(note *Note, created bool) (time.Time, bool) {
+	if created {
+		fm := ParseFrontmatter(note.Body)
+		for _, key := range []string{"created", "date"} {
+			for _, value := range fm.Get(key) {
+				if len(value) >= 10 {
+					if t, err := time.ParseInLocation(time.DateOnly, value[:10], time.Local); err == nil {
+						return t, true
+					}
+				}
+			}
+		}
+	}
+	info, err := os.Stat(note.Path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return info.ModTime(), true
+}
+
+// Filter returns the notes that match q.
+func (q *Query) Filter(notes []*Note) []*Note {
+	return q.FilterAt(notes, time.Now())
+}
+
+// FilterAt returns the notes that match q with relative dates measured
+// from now.
+func (q *Query) FilterAt(notes []*Note, now time.Time) []*Note {
+	env := &queryEnv{now: now, resolver: newResolver(notes), links: make(map[string]map[string]bool)}
+
+	var matched []*Note
+	for _, note := range notes {
+		if q.root.match(env, note) {
+			matched = append(matched, note)
+		}
+	}
+	return matched
+}
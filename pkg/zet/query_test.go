@@ -0,0 +1,136 @@
+package zet_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{expr: "", expected: "empty query at column 1"},
+		{expr: `title:"Road`, expected: "unterminated string at column 7"},
+		{expr: "title:/draft", expected: "unterminated regular expression at column 7"},
+		{expr: "tag: AND x", expected: "missing value for tag: at column 5"},
+		{expr: "a AND )", expected: `unexpected ")" at column 7`},
+		{expr: "(a OR b", expected: `unclosed "(" at column 1`},
+		{expr: "a AND", expected: "expected a term at column 6"},
+		{expr: "modified:>soon", expected: `invalid date "soon"`},
+		{expr: "size:>big", expected: `invalid number "big"`},
+		{expr: "title:>x", expected: "title: does not support > at column 7"},
+		{expr: "title:/(/", expected: "invalid regular expression"},
+		{expr: "modified:=7d", expected: "modified: an age takes <, <=, > or >=, or none for within it at column 10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := zet.ParseQuery(tt.expr)
+			if err == nil {
+				t.Fatalf("ParseQuery(%q) succeeded, want error", tt.expr)
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("ParseQuery(%q) error = %q, want it to contain %q", tt.expr, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestQueryErrorCaret(t *testing.T) {
+	_, err := zet.ParseQuery(`tag:a AND title:"open`)
+	if err == nil {
+		t.Fatal("ParseQuery() succeeded, want error")
+	}
+	expected := "unterminated string at column 17\n\n    tag:a AND title:\"open\n                    ^"
+	if err.Error() != expected {
+		t.Errorf("error = %q, want %q", err, expected)
+	}
+}
+
+func TestQueryFilter(t *testing.T) {
+	dir := ZetDir(t)
+	defer Cleanup(t, dir)
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.Local)
+	files := []struct {
+		name     string
+		body     string
+		modified time.Time
+	}{
+		{name: "Roadmap.md", body: "# Roadmap\n#project\nWhere we are going.\n", modified: now.AddDate(0, 0, -1)},
+		{name: "Plan.md", body: "---\nstatus: active\ncreated: 2026-01-05\n---\n# Plan\n#project/q4\nSee [[Roadmap]].\n", modified: now.AddDate(0, 0, -3)},
+		{name: "Draft Plan.md", body: "# Draft Plan\n#project\nRough idea for [[Roadmap]].\n", modified: now.AddDate(0, 0, -2)},
+		{name: "Old.md", body: "---\nstatus: archived\n---\n# Old\nSee [[Plan]]. A much longer body with many more words in it.\n", modified: now.AddDate(0, -2, 0)},
+	}
+
+	var notes []*zet.Note
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		if err := os.WriteFile(path, []byte(f.body), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", f.name, err)
+		}
+		if err := os.Chtimes(path, f.modified, f.modified); err != nil {
+			t.Fatalf("Failed to set times of %s: %v", f.name, err)
+		}
+		note, err := zet.ReadNote(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", f.name, err)
+		}
+		notes = append(notes, note)
+	}
+
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{expr: `tag:project AND modified:<7d AND links-to:"Roadmap" AND NOT title:/draft/`, expected: "Plan"},
+		{expr: "going", expected: "Roadmap"},
+		{expr: `"rough idea"`, expected: "Draft Plan"},
+		{expr: "title:plan", expected: "Plan,Draft Plan"},
+		{expr: "title:/^plan$/", expected: "Plan"},
+		{expr: "tag:project", expected: "Roadmap,Plan,Draft Plan"},
+		{expr: "tag:/q4/", expected: "Plan"},
+		{expr: "path:*Plan.md", expected: "Plan,Draft Plan"},
+		{expr: "path:/^old/", expected: "Old"},
+		{expr: "modified:>30d", expected: "Old"},
+		{expr: "modified:2d", expected: "Roadmap"},
+		{expr: "modified:3d", expected: "Roadmap,Draft Plan"},
+		{expr: "modified:yesterday", expected: "Roadmap"},
+		{expr: "modified:>=2026-10-17", expected: "Roadmap,Draft Plan"},
+		{expr: "created:<2026-02-01", expected: "Plan"},
+		{expr: "links-to:roadmap", expected: "Plan,Draft Plan"},
+		{expr: "links-to:/^Pl/", expected: "Old"},
+		{expr: "linked-from:Old", expected: "Plan"},
+		{expr: "words:>10", expected: "Old"},
+		{expr: "size:<=60", expected: "Roadmap,Draft Plan"},
+		{expr: "status:active", expected: "Plan"},
+		{expr: "status:/arch/", expected: "Old"},
+		{expr: "-tag:project", expected: "Old"},
+		{expr: "title:old OR title:roadmap AND going", expected: "Roadmap,Old"},
+		{expr: "(title:old OR title:roadmap) going", expected: "Roadmap"},
+		{expr: "NOT NOT title:old", expected: "Old"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			query, err := zet.ParseQuery(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseQuery() error = %v", err)
+			}
+
+			var got []string
+			for _, note := range query.FilterAt(notes, now) {
+				got = append(got, note.Title)
+			}
+			if strings.Join(got, ",") != tt.expected {
+				t.Errorf("FilterAt() = %v, want %s", got, tt.expected)
+			}
+		})
+	}
+}
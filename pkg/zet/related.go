@@ -0,0 +1,123 @@
+package zet
+
+import (
+	"math"
+	"sort"
+	"unicode/utf8"
+)
+
+type Related struct {
+	Note  *Note
+	Score float64 // Cosine similarity between 0 and 1
+}
+
+// RelatedNotes returns up to top notes most similar to note by the
+// cosine of their TF-IDF weighted terms, best first. Notes note links
+// to or is linked from are left out unless linked is set.
+func RelatedNotes(notes []*Note, note *Note, top int, linked bool) []Related {
+	vectors := termVectors(notes)
+
+	exclude := map[string]bool{note.Path: true}
+	if !linked {
+		r := newResolver(notes)
+		for _, link := range ParseLinks(note.Body) {
+			if target := r.lookup(link.Target); target != nil {
+				exclude[target.Path] = true
+			}
+		}
+		for _, source := range Backlinks(notes, note) {
+			exclude[source.Path] = true
+		}
+	}
+
+	var self map[string]float64
+	for i, n := range notes {
+		if n.Path == note.Path {
+			self = vectors[i]
+		}
+	}
+	if self == nil {
+		self = termVectors(append(append([]*Note{}, notes...), note))[len(notes)]
+	}
+
+	var related []Related
+	for i, n := range notes {
+		if exclude[n.Path] {
+			continue
+		}
+		if score := cosine(self, vectors[i]); score > 0 {
+			related = append(related, Related{Note: n, Score: score})
+		}
+	}
+
+	sort.SliceStable(related, func(i, j int) bool {
+		return related[i].Score > related[j].Score
+	})
+	if len(related) > top {
+		related = related[:top]
+	}
+	return related
+}
+
+// stopwords are common English words that say nothing about a topic.
+var stopwords = map[string]bool{
+	"a": true, "about": true, "all": true, "also": true, "an": true, "and": true,
+	"are": true, "as": true, "at": true, "be": true, "but": true, "by": true,
+	"can": true, "do": true, "for": true, "from": true, "has": true, "have": true,
+	"how": true, "if": true, "in": true, "into": true, "is": true, "it": true,
+	"its": true, "not": true, "of": true, "on": true, "or": true, "so": true,
+	"see": true, "that": true, "the": true, "their": true, "then": true, "there": true,
+	"these": true, "this": true, "to": true, "was": true, "we": true, "what": true,
+	"when": true, "which": true, "with": true, "you": true,
+}
+
+// termVectors returns the unit length TF-IDF vector of each of notes,
+// using sublinear term frequencies and ignoring stopwords and one
+// letter words.
+func termVectors(notes []*Note) []map[string]float64 {
+	counts := make([]map[string]int, len(notes))
+	documents := make(map[string]int)
+	for i, note := range notes {
+		counts[i] = make(map[string]int)
+		for _, term := range Tokenize(note.Title + "\n" + StripFrontmatter(note.Body)) {
+			if utf8.RuneCountInString(term) < 2 || stopwords[term] {
+				continue
+			}
+			if counts[i][term] == 0 {
+				documents[term]++
+			}
+			counts[i][term]++
+		}
+	}
+
+	vectors := make([]map[string]float64, len(notes))
+	for i := range notes {
+		vector := make(map[string]float64)
+		norm := 0.0
+		for term, count := range counts[i] {
+			idf := math.Log(float64(len(notes)) / float64(documents[term]))
+			weight := (1 + math.Log(float64(count))) * idf
+			if weight > 0 {
+				vector[term] = weight
+				norm += weight * weight
+			}
+		}
+		norm = math.Sqrt(norm)
+		for term := range vector {
+			vector[term] /= norm
+		}
+		vectors[i] = vector
+	}
+	return vectors
+}
+
+func cosine(a, b map[string]float64) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	dot := 0.0
+	for term, weight := range a {
+		dot += weight * b[term]
+	}
+	return dot
+}
@@ -0,0 +1,35 @@
+package zet_test
+
+import (
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestRelatedNotes(t *testing.T) {
+	notes := []*zet.Note{
+		{Title: "Goroutines", Path: "/tmp/Goroutines.md", Body: "# Goroutines\nGo concurrency with channels and goroutines. See [[Channels]]."},
+		{Title: "Channels", Path: "/tmp/Channels.md", Body: "# Channels\nChannels pass values between goroutines."},
+		{Title: "Scheduler", Path: "/tmp/Scheduler.md", Body: "# Scheduler\nThe Go scheduler runs goroutines on threads."},
+		{Title: "Mutexes", Path: "/tmp/Mutexes.md", Body: "# Mutexes\nLocks guard shared state in concurrency."},
+		{Title: "Sourdough", Path: "/tmp/Sourdough.md", Body: "# Sourdough\nFlour, water and salt."},
+		{Title: "Index", Path: "/tmp/Index.md", Body: "# Index\n[[Goroutines]] and concurrency"},
+	}
+
+	related := zet.RelatedNotes(notes, notes[0], 10, false)
+	var titles []string
+	for _, r := range related {
+		titles = append(titles, r.Note.Title)
+		if r.Score <= 0 || r.Score > 1.0001 {
+			t.Errorf("score of %s = %f, want within (0, 1]", r.Note.Title, r.Score)
+		}
+	}
+	if len(titles) != 2 || titles[0] != "Scheduler" || titles[1] != "Mutexes" {
+		t.Errorf("RelatedNotes() = %v, want [Scheduler Mutexes]", titles)
+	}
+
+	related = zet.RelatedNotes(notes, notes[0], 1, true)
+	if len(related) != 1 || related[0].Note.Title != "Channels" {
+		t.Errorf("RelatedNotes() with linked notes = %v, want Channels first", related)
+	}
+}
@@ -0,0 +1,179 @@
+package zet
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// QueryBlockLang is the info string of fenced code blocks whose query
+// is replaced by a list of the matching notes when a note is expanded.
+const QueryBlockLang = "zet-query"
+
+func GetSavedQueriesPath(dir string) string {
+	return filepath.Join(GetVaultConfigDir(dir), "saved")
+}
+
+// ReadSavedQueries returns the saved queries of the vault in dir by
+// name, from the "name = expression" lines of its saved file.
+func ReadSavedQueries(dir string) (map[string]string, error) {
+	file, err := os.Open(GetSavedQueriesPath(dir))
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	saved := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, expr, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		saved[strings.TrimSpace(name)] = strings.TrimSpace(expr)
+	}
+	return saved, scanner.Err()
+}
+
+// SaveQuery saves expr under name in the vault in dir, replacing any
+// query saved under that name, or removes the query when expr is empty.
+func SaveQuery(dir, name, expr string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.ContainsAny(name, "=\n") || strings.HasPrefix(name, "#") {
+		return fmt.Errorf("invalid name %q", name)
+	}
+	if expr != "" {
+		if _, err := ParseQuery(expr); err != nil {
+			return err
+		}
+	}
+
+	saved, err := ReadSavedQueries(dir)
+	if err != nil {
+		return err
+	}
+	if expr == "" {
+		if _, ok := saved[name]; !ok {
+			return fmt.Errorf("no saved query %q", name)
+		}
+		delete(saved, name)
+	} else {
+		saved[name] = expr
+	}
+
+	var names []string
+	for name := range saved {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s = %s\n", name, saved[name])
+	}
+
+	err = os.MkdirAll(GetVaultConfigDir(dir), 0755)
+	if err != nil {
+		return err
+	}
+	return AtomicWriteFile(GetSavedQueriesPath(dir), []byte(b.String()), 0644)
+}
+
+// SavedQuery returns the query saved under name, matched
+// case-insensitively if there is no exact match.
+func SavedQuery(dir, name string) (*Query, error) {
+	saved, err := ReadSavedQueries(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	expr, ok := saved[name]
+	if !ok {
+		for other, e := range saved {
+			if strings.EqualFold(other, name) {
+				expr, ok = e, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("no saved query %q", name)
+	}
+	return ParseQuery(expr)
+}
+
+// ExpandNote returns the body of note with its embeds and query blocks
+// expanded, as it is rendered and exported.
+func ExpandNote(notes []*Note, note *Note) string {
+	return ExpandQueryBlocks(notes, note, ExpandEmbeds(notes, note, DefaultEmbedDepth))
+}
+
+// ExpandQueryBlocks returns body, of note, with every ```zet-query
+// block replaced by a list of links to the notes in notes matching its
+// query, other than note itself. A query that fails to parse is
+// replaced by its error.
+func ExpandQueryBlocks(notes []*Note, note *Note, body string) string {
+	lines := strings.Split(body, "\n")
+
+	var out []string
+	fence := ""
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			out = append(out, lines[i])
+			continue
+		}
+		if !strings.HasPrefix(trimmed, "```") && !strings.HasPrefix(trimmed, "~~~") {
+			out = append(out, lines[i])
+			continue
+		}
+
+		open := trimmed[:3]
+		if strings.TrimSpace(strings.TrimLeft(trimmed, open[:1])) != QueryBlockLang {
+			fence = open
+			out = append(out, lines[i])
+			continue
+		}
+
+		end := i + 1
+		for end < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[end]), open) {
+			end++
+		}
+		expr := strings.Join(strings.Fields(strings.Join(lines[i+1:min(end, len(lines))], " ")), " ")
+		out = append(out, queryBlockList(notes, note, expr)...)
+		i = end
+	}
+	return strings.Join(out, "\n")
+}
+
+func queryBlockList(notes []*Note, note *Note, expr string) []string {
+	query, err := ParseQuery(expr)
+	if err != nil {
+		msg, _, _ := strings.Cut(err.Error(), "\n")
+		return []string{"> Query error: " + msg}
+	}
+
+	var list []string
+	for _, match := range query.Filter(notes) {
+		if match.Path != note.Path {
+			list = append(list, "- "+FormatWikiLink(Link{Target: match.Title}))
+		}
+	}
+	if len(list) == 0 {
+		return []string{"> No notes match " + expr}
+	}
+	return list
+}
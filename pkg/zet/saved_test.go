@@ -0,0 +1,124 @@
+package zet_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestSaveQuery(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	err := zet.SaveQuery(zetDir, "this week", "modified:<7d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = zet.SaveQuery(zetDir, "drafts", "title:/draft/ OR status:draft")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(zet.GetSavedQueriesPath(zetDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "drafts = title:/draft/ OR status:draft\nthis week = modified:<7d\n"
+	if string(content) != expected {
+		t.Errorf("saved file = %q, want %q", content, expected)
+	}
+
+	query, err := zet.SavedQuery(zetDir, "This Week")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Expr != "modified:<7d" {
+		t.Errorf("SavedQuery() = %q, want %q", query.Expr, "modified:<7d")
+	}
+
+	if err := zet.SaveQuery(zetDir, "broken", "tag:"); err == nil {
+		t.Error("SaveQuery() with an invalid query should fail")
+	}
+	if err := zet.SaveQuery(zetDir, "a = b", "tag:x"); err == nil {
+		t.Error("SaveQuery() with '=' in the name should fail")
+	}
+
+	err = zet.SaveQuery(zetDir, "drafts", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := zet.ReadSavedQueries(zetDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := saved["drafts"]; ok || len(saved) != 1 {
+		t.Errorf("ReadSavedQueries() after removal = %v", saved)
+	}
+	if _, err := zet.SavedQuery(zetDir, "drafts"); err == nil {
+		t.Error("SavedQuery() of a removed query should fail")
+	}
+}
+
+func TestExpandQueryBlocks(t *testing.T) {
+	notes := []*zet.Note{
+		{Title: "Projects", Path: "/tmp/Projects.md", Body: "# Projects\n#project\n"},
+		{Title: "Alpha", Path: "/tmp/Alpha.md", Body: "# Alpha\n#project\n"},
+		{Title: "Beta", Path: "/tmp/Beta.md", Body: "# Beta\n#project #done\n"},
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "list",
+			body:     "# Projects\n```zet-query\ntag:project\n  NOT tag:done\n```\nAfter",
+			expected: "# Projects\n- [[Alpha]]\nAfter",
+		},
+		{
+			name:     "excludes self",
+			body:     "~~~ zet-query\ntag:project\n~~~",
+			expected: "- [[Alpha]]\n- [[Beta]]",
+		},
+		{
+			name:     "no matches",
+			body:     "```zet-query\ntag:missing\n```",
+			expected: "> No notes match tag:missing",
+		},
+		{
+			name:     "error",
+			body:     "```zet-query\ntitle:\"open\n```",
+			expected: "> Query error: unterminated string at column 7",
+		},
+		{
+			name:     "other code blocks",
+			body:     "````md\n```zet-query\ntag:project\n```\n````",
+			expected: "````md\n```zet-query\ntag:project\n```\n````",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := zet.ExpandQueryBlocks(notes, notes[0], tt.body)
+			if got != tt.expected {
+				t.Errorf("ExpandQueryBlocks() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExpandNote(t *testing.T) {
+	notes := []*zet.Note{
+		{Title: "Index", Path: "/tmp/Index.md", Body: "# Index\n![[Open]]\n"},
+		{Title: "Open", Path: "/tmp/Open.md", Body: "# Open\n```zet-query\ntag:open\n```\n"},
+		{Title: "Task", Path: "/tmp/Task.md", Body: "# Task\n#open\n"},
+	}
+
+	got := zet.ExpandNote(notes, notes[0])
+	if !strings.Contains(got, "- [[Task]]") || strings.Contains(got, "zet-query") {
+		t.Errorf("ExpandNote() = %q, want the embedded query expanded", got)
+	}
+}
@@ -0,0 +1,100 @@
+package zet
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SplitCommand splits a command line into words the way a POSIX shell
+// would, honouring single and double quotes and backslash escapes, and
+// expanding $VAR, ${VAR} and a leading ~ outside single quotes.
+func SplitCommand(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	quote := rune(0)
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\' && i+1 < len(runes) && (quote == 0 || strings.ContainsRune(`"\$`, runes[i+1])):
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case quote == '"' && r == '"':
+			quote = 0
+		case r == '$':
+			name, n := envName(runes[i+1:])
+			if n == 0 {
+				word.WriteRune(r)
+			} else {
+				word.WriteString(os.Getenv(name))
+				i += n
+			}
+			inWord = true
+		case quote == '"':
+			word.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case r == '~' && !inWord && (i+1 == len(runes) || runes[i+1] == '/'):
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, err
+			}
+			word.WriteString(home)
+			inWord = true
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in %q", quote, line)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// envName returns the variable name at the start of runes, written as
+// NAME or {NAME}, and how many runes it spans.
+func envName(runes []rune) (string, int) {
+	if len(runes) > 0 && runes[0] == '{' {
+		for i := 1; i < len(runes); i++ {
+			if runes[i] == '}' {
+				return string(runes[1:i]), i + 1
+			}
+		}
+		return "", 0
+	}
+
+	n := 0
+	for n < len(runes) && (runes[n] == '_' || runes[n] >= 'a' && runes[n] <= 'z' ||
+		runes[n] >= 'A' && runes[n] <= 'Z' || n > 0 && runes[n] >= '0' && runes[n] <= '9') {
+		n++
+	}
+	return string(runes[:n]), n
+}
+
+// ShellQuote returns word quoted so that a POSIX shell reads it back as
+// a single word.
+func ShellQuote(word string) string {
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
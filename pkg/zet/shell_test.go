@@ -0,0 +1,72 @@
+package zet_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestSplitCommand(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	originalValue := os.Getenv("ZET_TEST_VAR")
+	os.Setenv("ZET_TEST_VAR", "value")
+	defer func() {
+		if originalValue != "" {
+			os.Setenv("ZET_TEST_VAR", originalValue)
+		} else {
+			os.Unsetenv("ZET_TEST_VAR")
+		}
+	}()
+
+	tests := []struct {
+		name     string
+		line     string
+		expected []string
+		wantErr  bool
+	}{
+		{name: "single word", line: "vim", expected: []string{"vim"}},
+		{name: "arguments", line: "code  --wait", expected: []string{"code", "--wait"}},
+		{name: "tilde", line: "nvim -u ~/x.vim", expected: []string{"nvim", "-u", home + "/x.vim"}},
+		{name: "single quotes", line: `ed '$HOME and "x"'`, expected: []string{"ed", `$HOME and "x"`}},
+		{name: "double quotes", line: `ed "a $ZET_TEST_VAR \"b\""`, expected: []string{"ed", `a value "b"`}},
+		{name: "braced variable", line: "ed ${ZET_TEST_VAR}s", expected: []string{"ed", "values"}},
+		{name: "escaped space", line: `/opt/my\ editor -w`, expected: []string{"/opt/my editor", "-w"}},
+		{name: "empty quotes", line: `ed ""`, expected: []string{"ed", ""}},
+		{name: "unterminated quote", line: `ed "oops`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := zet.SplitCommand(tt.line)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("SplitCommand(%q) expected error", tt.line)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SplitCommand(%q) error = %v", tt.line, err)
+			}
+			if strings.Join(result, "|") != strings.Join(tt.expected, "|") || len(result) != len(tt.expected) {
+				t.Errorf("SplitCommand(%q) = %q, want %q", tt.line, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	for _, word := range []string{"plain", "with space", "it's", `a "b" $c`, ""} {
+		result, err := zet.SplitCommand(zet.ShellQuote(word))
+		if err != nil {
+			t.Fatalf("SplitCommand(ShellQuote(%q)) error = %v", word, err)
+		}
+		if len(result) != 1 || result[0] != word {
+			t.Errorf("SplitCommand(ShellQuote(%q)) = %q", word, result)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package zet
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Section is the part of a note under a heading, up to the next heading
+// of the same or a higher level.
+type Section struct {
+	Heading Heading
+	Start   int // 0-based index of the heading line
+	End     int // 0-based index of the line just past the section
+}
+
+// ParseSections returns the sections of body under headings of level.
+func ParseSections(body string, level int) []Section {
+	headings := ParseHeadings(body)
+	total := len(strings.Split(body, "\n"))
+
+	var sections []Section
+	for i := range headings {
+		if headings[i].Level != level {
+			continue
+		}
+		end := sectionEnd(headings, &headings[i], total)
+		sections = append(sections, Section{Heading: headings[i], Start: headings[i].Line - 1, End: end})
+	}
+	return sections
+}
+
+// PlanSplit returns the changes that move sections of note into notes
+// of their own, titled by their headings. Each section is replaced in
+// note by a link to its new note, or an embed of it when transclude is
+// set. Links to headings and blocks that moved, anywhere in notes, are
+// pointed at the new notes.
+func PlanSplit(notes []*Note, note *Note, sections []Section, transclude bool) ([]FileChange, error) {
+	dir := filepath.Dir(note.Path)
+	lines := strings.Split(note.Body, "\n")
+	headings := ParseHeadings(note.Body)
+
+	moved := make([]*Note, len(sections))
+	for i, section := range sections {
+		title := section.Heading.Text
+		if existing := LookupNote(notes, title); existing != nil {
+			return nil, fmt.Errorf("cannot split %q: note %q exists", title, existing.Title)
+		}
+		filename, err := NoteFilename(dir, title)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := FindNoteFile(dir, filename); ok {
+			return nil, fmt.Errorf("cannot split %q: %s exists", title, filename)
+		}
+		for _, other := range moved[:i] {
+			if other.Path == filepath.Join(dir, filename) {
+				return nil, fmt.Errorf("cannot split %q: two sections map to %s", title, filename)
+			}
+		}
+		moved[i] = &Note{Title: title, Path: filepath.Join(dir, filename)}
+	}
+
+	// destination returns the note an anchor of note ends up in, and
+	// whether the anchor is the heading that became its title
+	destination := func(link Link) (*Note, bool) {
+		line, ok := AnchorLine(note.Body, link)
+		if !ok {
+			return note, false
+		}
+		for i, section := range sections {
+			if line > section.Start && line <= section.End {
+				return moved[i], line == section.Start+1
+			}
+		}
+		return note, false
+	}
+
+	// relink points the anchored links to note in body, the body of
+	// self, at the notes their anchors moved to. self is nil for notes
+	// other than note and the new notes.
+	r := newResolver(notes)
+	relink := func(body string, self *Note) string {
+		return EditLinks(body, func(link Link, text string) (string, bool) {
+			local := link.Target == ""
+			if link.Anchor() == "" || local && self == nil {
+				return "", false
+			}
+			if !local {
+				if linked := r.lookup(link.Target); linked == nil || linked.Path != note.Path {
+					return "", false
+				}
+			}
+
+			dest, title := destination(link)
+			if local && dest.Path == self.Path || !local && dest.Path == note.Path {
+				return "", false
+			}
+			if title {
+				link.Heading = ""
+			}
+			return FormatLink(text, link, dest), true
+		})
+	}
+
+	var changes []FileChange
+	for i, section := range sections {
+		content := make([]string, 0, section.End-section.Start)
+		for j := section.Start + 1; j < section.End; j++ {
+			content = append(content, lines[j])
+		}
+		for _, heading := range headings {
+			if heading.Line-1 > section.Start && heading.Line-1 < section.End {
+				content[heading.Line-2-section.Start] = strings.TrimPrefix(lines[heading.Line-1], strings.Repeat("#", section.Heading.Level-1))
+			}
+		}
+		content = trimBlankLines(trimBlankLines(content, true), false)
+
+		body := "# " + moved[i].Title + "\n"
+		if len(content) > 0 {
+			body += "\n" + strings.Join(content, "\n") + "\n"
+		}
+		moved[i].Body = relink(body, moved[i])
+		changes = append(changes, FileChange{Path: moved[i].Path, After: moved[i].Body})
+	}
+
+	var remaining []string
+	last := 0
+	for i, section := range sections {
+		remaining = append(remaining, lines[last:section.Start]...)
+		link := Link{Target: moved[i].Title, Embed: transclude}
+		if transclude {
+			remaining = append(remaining, FormatWikiLink(link))
+		} else {
+			remaining = append(remaining, "- "+FormatWikiLink(link))
+		}
+		last = section.End
+		next := i+1 < len(sections) && sections[i+1].Start == section.End
+		if !next && last < len(lines) && strings.TrimSpace(lines[last]) != "" {
+			remaining = append(remaining, "")
+		}
+	}
+	remaining = append(remaining, lines[last:]...)
+	body := relink(strings.Join(remaining, "\n"), note)
+	changes = append(changes, FileChange{Path: note.Path, Before: note.Body, After: body})
+
+	for _, other := range notes {
+		if other.Path == note.Path {
+			continue
+		}
+		if body := relink(other.Body, nil); body != other.Body {
+			changes = append(changes, FileChange{Path: other.Path, Before: other.Body, After: body})
+		}
+	}
+	return changes, nil
+}
+
+// SplitNote applies the changes of PlanSplit.
+func SplitNote(notes []*Note, note *Note, sections []Section, transclude bool) error {
+	changes, err := PlanSplit(notes, note, sections, transclude)
+	if err != nil {
+		return err
+	}
+
+	var paths []string
+	for _, change := range changes {
+		paths = append(paths, change.Path)
+		err = AtomicWriteFile(change.Path, []byte(change.After), 0644)
+		if err != nil {
+			return err
+		}
+
+		event := HookEdited
+		if change.Before == "" {
+			event = HookCreated
+		}
+		if n, err := ReadNote(change.Path); err == nil {
+			fireHook(event, n.Path, n.Title, "")
+		}
+	}
+
+	RefreshDaemon(filepath.Dir(note.Path), paths...)
+	return nil
+}
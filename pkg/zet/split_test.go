@@ -0,0 +1,116 @@
+package zet_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestParseSections(t *testing.T) {
+	body := "# Title\nintro\n## One\na\n### Sub\nb\n## Two\nc\n# Other\n## Three"
+
+	sections := zet.ParseSections(body, 2)
+
+	expected := []struct {
+		text       string
+		start, end int
+	}{
+		{"One", 2, 6},
+		{"Two", 6, 8},
+		{"Three", 9, 10},
+	}
+	if len(sections) != len(expected) {
+		t.Fatalf("ParseSections() = %v, want %d sections", sections, len(expected))
+	}
+	for i, want := range expected {
+		got := sections[i]
+		if got.Heading.Text != want.text || got.Start != want.start || got.End != want.end {
+			t.Errorf("section %d = %s [%d, %d), want %s [%d, %d)", i, got.Heading.Text, got.Start, got.End, want.text, want.start, want.end)
+		}
+	}
+}
+
+func TestSplitNote(t *testing.T) {
+	tests := []struct {
+		name       string
+		transclude bool
+		expected   map[string]string
+	}{
+		{
+			name: "links",
+			expected: map[string]string{
+				"Long.md":   "# Long\n\nIntro, see [[#Keep]] and [[Two#Deep]].\n\n- [[One]]\n- [[Two]]\n\n## Keep\n\nStays\n",
+				"One.md":    "# One\n\nFirst, see [[Two]] and [[Long#Keep]]. ^b1\n",
+				"Two.md":    "# Two\n\nSecond\n\n## Deep\n\nDeeper, see [[#Deep]].\n",
+				"Linker.md": "# Linker\n\n[[One]] [[One#^b1]] [[Two#Deep]] [[Long#Keep]] [[Long]]\n",
+			},
+		},
+		{
+			name:       "transclusion",
+			transclude: true,
+			expected: map[string]string{
+				"Long.md": "# Long\n\nIntro, see [[#Keep]] and [[Two#Deep]].\n\n![[One]]\n![[Two]]\n\n## Keep\n\nStays\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zetDir := ZetDir(t)
+			defer Cleanup(t, zetDir)
+
+			files := map[string]string{
+				"Long.md":   "# Long\n\nIntro, see [[#Keep]] and [[#Deep]].\n\n## One\n\nFirst, see [[#Two]] and [[#Keep]]. ^b1\n\n## Two\n\nSecond\n\n### Deep\n\nDeeper, see [[#Deep]].\n\n## Keep\n\nStays\n",
+				"Linker.md": "# Linker\n\n[[Long#One]] [[Long#^b1]] [[Long#Deep]] [[Long#Keep]] [[Long]]\n",
+			}
+			var notes []*zet.Note
+			for _, name := range []string{"Linker.md", "Long.md"} {
+				path := filepath.Join(zetDir, name)
+				err := os.WriteFile(path, []byte(files[name]), 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+				note, err := zet.ReadNote(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				notes = append(notes, note)
+			}
+			// Load the note apart from the vault, as zet split does
+			long, err := zet.ReadNote(filepath.Join(zetDir, "Long.md"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sections := zet.ParseSections(long.Body, 2)
+			err = zet.SplitNote(notes, long, sections[:2], tt.transclude)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for name, want := range tt.expected {
+				content, err := os.ReadFile(filepath.Join(zetDir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(content) != want {
+					t.Errorf("%s after split = %q, want %q", name, content, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitNoteExisting(t *testing.T) {
+	notes := []*zet.Note{
+		{Title: "Long", Path: "/tmp/Long.md", Body: "# Long\n## One\ntext"},
+		{Title: "One", Path: "/tmp/One.md", Body: "# One\n"},
+	}
+
+	_, err := zet.PlanSplit(notes, notes[0], zet.ParseSections(notes[0].Body, 2), false)
+	if err == nil {
+		t.Error("PlanSplit() onto an existing note should fail")
+	}
+}
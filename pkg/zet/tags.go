@@ -0,0 +1,68 @@
+package zet
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var inlineTagRe = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_/-]+)`)
+
+// ParseTags returns the tags of body, from the tags or tag frontmatter
+// field and from inline #tags outside code, without the leading '#'.
+// Tags are deduplicated case-insensitively and sorted.
+func ParseTags(body string) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	add := func(tag string) {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+		if tag == "" || seen[strings.ToLower(tag)] {
+			return
+		}
+		seen[strings.ToLower(tag)] = true
+		tags = append(tags, tag)
+	}
+
+	fm := ParseFrontmatter(body)
+	for _, key := range []string{"tags", "tag"} {
+		for _, value := range fm.Get(key) {
+			for _, tag := range strings.FieldsFunc(value, func(r rune) bool {
+				return r == ',' || unicode.IsSpace(r)
+			}) {
+				add(tag)
+			}
+		}
+	}
+
+	_, rest := SplitFrontmatter(body)
+	for _, line := range CodeMaskedLines(rest) {
+		for _, m := range inlineTagRe.FindAllStringSubmatch(line, -1) {
+			if strings.TrimFunc(m[1], unicode.IsDigit) != "" {
+				add(m[1])
+			}
+		}
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		return strings.ToLower(tags[i]) < strings.ToLower(tags[j])
+	})
+	return tags
+}
+
+// HasTag reports whether note is tagged tag or, for nested tags, with a
+// tag below it such as tag/child.
+func HasTag(note *Note, tag string) bool {
+	return includesTag(ParseTags(note.Body), tag)
+}
+
+func includesTag(tags []string, tag string) bool {
+	tag = strings.ToLower(strings.TrimPrefix(tag, "#"))
+	for _, t := range tags {
+		t = strings.ToLower(t)
+		if t == tag || strings.HasPrefix(t, tag+"/") {
+			return true
+		}
+	}
+	return false
+}
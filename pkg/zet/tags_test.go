@@ -0,0 +1,75 @@
+package zet_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{
+			name:     "inline tags",
+			body:     "# Title\nAbout #go and #project/zet.",
+			expected: []string{"go", "project/zet"},
+		},
+		{
+			name:     "frontmatter list",
+			body:     "---\ntags: [b, \"#a\"]\n---\n# Title\n",
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "frontmatter scalar",
+			body:     "---\ntags: x, y z\n---\n",
+			expected: []string{"x", "y", "z"},
+		},
+		{
+			name:     "deduplicated case-insensitively",
+			body:     "---\ntags: [Go]\n---\n#go",
+			expected: []string{"Go"},
+		},
+		{
+			name:     "headings links and numbers are not tags",
+			body:     "## Heading\n[[Note#Section]] issue #42 a#b",
+			expected: nil,
+		},
+		{
+			name:     "code is skipped",
+			body:     "`#inline`\n```\n#fenced\n```\n#real",
+			expected: []string{"real"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := zet.ParseTags(tt.body)
+			if strings.Join(got, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("ParseTags() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHasTag(t *testing.T) {
+	note := &zet.Note{Title: "Note", Body: "#Project/Zet"}
+
+	tests := []struct {
+		tag      string
+		expected bool
+	}{
+		{"project/zet", true},
+		{"#project", true},
+		{"proj", false},
+		{"zet", false},
+	}
+	for _, tt := range tests {
+		if got := zet.HasTag(note, tt.tag); got != tt.expected {
+			t.Errorf("HasTag(%q) = %v, want %v", tt.tag, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package zet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Task is a "- [ ]" or "- [x]" checkbox item in a note.
+type Task struct {
+	Note  *Note    `json:"-"`
+	Title string   `json:"note"`
+	Path  string   `json:"path"`
+	Line  int      `json:"line"` // 1-based line number of the task
+	Text  string   `json:"text"` // The task without its checkbox
+	Done  bool     `json:"done"`
+	Due   string   `json:"due,omitempty"` // Due date as YYYY-MM-DD
+	Tags  []string `json:"tags,omitempty"`
+
+	raw string // The line as read, to detect changes before toggling
+}
+
+// TaskFilter narrows the tasks returned by FilterTasks. Zero values
+// match every task.
+type TaskFilter struct {
+	Tag  string    // Tag on the task or its note
+	Note *Note     // Note the task is in
+	Due  time.Time // Due on or before this day
+	Done bool      // Include completed tasks
+}
+
+var (
+	taskRe = regexp.MustCompile(`^(\s*[-*+]\s+\[)([ xX])(\]\s+)(.*)$`)
+	dueRe  = regexp.MustCompile(`(?:📅\s*|due:)(\d{4}-\d{2}-\d{2})`)
+)
+
+// ParseTasks returns the tasks of note outside code blocks.
+func ParseTasks(note *Note) []Task {
+	lines := strings.Split(note.Body, "\n")
+
+	var tasks []Task
+	for i, masked := range CodeMaskedLines(note.Body) {
+		if strings.TrimSpace(masked) == "" {
+			continue
+		}
+		m := taskRe.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		task := Task{
+			Note:  note,
+			Title: note.Title,
+			Path:  note.Path,
+			Line:  i + 1,
+			Text:  strings.TrimSpace(m[4]),
+			Done:  m[2] != " ",
+			Tags:  ParseTags(m[4]),
+			raw:   lines[i],
+		}
+		if due := dueRe.FindStringSubmatch(m[4]); due != nil {
+			task.Due = due[1]
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// FilterTasks returns the tasks of notes that match filter, in order.
+func FilterTasks(notes []*Note, filter TaskFilter) []Task {
+	var matched []Task
+	for _, note := range notes {
+		if filter.Note != nil && note.Path != filter.Note.Path {
+			continue
+		}
+		tasks := ParseTasks(note)
+
+		// Tags on other tasks do not tag the whole note
+		noteTagged := filter.Tag == ""
+		if !noteTagged {
+			lines := strings.Split(note.Body, "\n")
+			for _, task := range tasks {
+				lines[task.Line-1] = ""
+			}
+			noteTagged = includesTag(ParseTags(strings.Join(lines, "\n")), filter.Tag)
+		}
+
+		for _, task := range tasks {
+			if task.Done && !filter.Done {
+				continue
+			}
+			if !noteTagged && !includesTag(task.Tags, filter.Tag) {
+				continue
+			}
+			if !filter.Due.IsZero() && (task.Due == "" || task.Due > filter.Due.Format(time.DateOnly)) {
+				continue
+			}
+			matched = append(matched, task)
+		}
+	}
+	return matched
+}
+
+// ParseDueDate parses a due date filter: a YYYY-MM-DD date, "today",
+// "tomorrow" or a number of days from now such as "+7d".
+func ParseDueDate(value string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch value {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+
+	if strings.HasPrefix(value, "+") && strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(value[1 : len(value)-1])
+		if err == nil {
+			return today.AddDate(0, 0, days), nil
+		}
+	}
+
+	date, err := time.ParseInLocation(time.DateOnly, value, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid due date %q (want YYYY-MM-DD, today, tomorrow or +Nd)", value)
+	}
+	return date, nil
+}
+
+// ToggleTask checks task off, or unchecks it if it is done, in its note
+// file, unless the line has changed since the task was read.
+func ToggleTask(task Task) error {
+	content, err := os.ReadFile(task.Path)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(string(content), "\n")
+	if task.Line > len(lines) || lines[task.Line-1] != task.raw {
+		return fmt.Errorf("%s:%d changed since it was read", task.Title, task.Line)
+	}
+
+	mark := "x"
+	if task.Done {
+		mark = " "
+	}
+	lines[task.Line-1] = taskRe.ReplaceAllString(task.raw, "${1}"+mark+"${3}${4}")
+
+	err = AtomicWriteFile(task.Path, []byte(strings.Join(lines, "\n")), 0644)
+	if err != nil {
+		return err
+	}
+
+	RefreshDaemon(filepath.Dir(task.Path), task.Path)
+	fireHook(HookEdited, task.Path, task.Title, "")
+	return nil
+}
+
+// FindTask lets the user pick one of tasks with fzf.
+func FindTask(tasks []Task, searchTerm string) (*Task, error) {
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("no tasks")
+	}
+
+	var lines []string
+	for i, task := range tasks {
+		lines = append(lines, fmt.Sprintf("%d\t%s\t%s", i, FormatTask(task), task.Path))
+	}
+
+	output, err := runFzf(strings.Join(lines, "\n"), searchTerm, "cat {3}")
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := parseFzfIndex(output, len(tasks))
+	if err != nil {
+		return nil, err
+	}
+	return &tasks[index], nil
+}
+
+// FormatTask returns task as a line of the form "[ ] Note:12: text".
+func FormatTask(task Task) string {
+	mark := "[ ]"
+	if task.Done {
+		mark = "[x]"
+	}
+	return fmt.Sprintf("%s %s:%d: %s", mark, task.Title, task.Line, task.Text)
+}
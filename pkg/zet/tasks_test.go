@@ -0,0 +1,135 @@
+package zet_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestParseTasks(t *testing.T) {
+	note := &zet.Note{Title: "Plan", Path: "/tmp/Plan.md", Body: "# Plan\n- [ ] Write draft 📅 2026-10-20\n  * [x] Outline #writing\n- [ ]no space\n```\n- [ ] in code\n```\n+ [X] Ship due:2026-11-01\n- plain item"}
+
+	tasks := zet.ParseTasks(note)
+
+	expected := []string{
+		"[ ] Plan:2: Write draft 📅 2026-10-20",
+		"[x] Plan:3: Outline #writing",
+		"[x] Plan:8: Ship due:2026-11-01",
+	}
+	if len(tasks) != len(expected) {
+		t.Fatalf("ParseTasks() = %v, want %d tasks", tasks, len(expected))
+	}
+	for i, want := range expected {
+		if got := zet.FormatTask(tasks[i]); got != want {
+			t.Errorf("task %d = %q, want %q", i, got, want)
+		}
+	}
+	if tasks[0].Due != "2026-10-20" || tasks[2].Due != "2026-11-01" || tasks[1].Due != "" {
+		t.Errorf("due dates = %q %q %q", tasks[0].Due, tasks[1].Due, tasks[2].Due)
+	}
+	if len(tasks[1].Tags) != 1 || tasks[1].Tags[0] != "writing" {
+		t.Errorf("task tags = %v, want [writing]", tasks[1].Tags)
+	}
+}
+
+func TestFilterTasks(t *testing.T) {
+	notes := []*zet.Note{
+		{Title: "Work", Path: "/tmp/Work.md", Body: "#job\n- [ ] Report due:2026-10-19\n- [x] Email\n"},
+		{Title: "Home", Path: "/tmp/Home.md", Body: "- [ ] Paint #diy\n- [ ] Taxes 📅 2026-12-01\n"},
+	}
+	due := time.Date(2026, 10, 20, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		filter   zet.TaskFilter
+		expected string
+	}{
+		{name: "open", filter: zet.TaskFilter{}, expected: "Report due:2026-10-19,Paint #diy,Taxes 📅 2026-12-01"},
+		{name: "done", filter: zet.TaskFilter{Done: true}, expected: "Report due:2026-10-19,Email,Paint #diy,Taxes 📅 2026-12-01"},
+		{name: "note tag", filter: zet.TaskFilter{Tag: "job"}, expected: "Report due:2026-10-19"},
+		{name: "task tag", filter: zet.TaskFilter{Tag: "diy"}, expected: "Paint #diy"},
+		{name: "note", filter: zet.TaskFilter{Note: notes[1]}, expected: "Paint #diy,Taxes 📅 2026-12-01"},
+		{name: "due", filter: zet.TaskFilter{Due: due}, expected: "Report due:2026-10-19"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, task := range zet.FilterTasks(notes, tt.filter) {
+				got = append(got, task.Text)
+			}
+			if strings.Join(got, ",") != tt.expected {
+				t.Errorf("FilterTasks() = %q, want %q", strings.Join(got, ","), tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseDueDate(t *testing.T) {
+	now := time.Date(2026, 10, 19, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected string
+		wantErr  bool
+	}{
+		{value: "today", expected: "2026-10-19"},
+		{value: "tomorrow", expected: "2026-10-20"},
+		{value: "+14d", expected: "2026-11-02"},
+		{value: "2027-01-05", expected: "2027-01-05"},
+		{value: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		date, err := zet.ParseDueDate(tt.value, now)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseDueDate(%q) expected error", tt.value)
+			}
+			continue
+		}
+		if err != nil || date.Format(time.DateOnly) != tt.expected {
+			t.Errorf("ParseDueDate(%q) = %v, %v, want %s", tt.value, date, err, tt.expected)
+		}
+	}
+}
+
+func TestToggleTask(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	path := filepath.Join(zetDir, "Todo.md")
+	err := os.WriteFile(path, []byte("# Todo\n- [ ] One\n  - [x] Two\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	note, err := zet.ReadNote(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasks := zet.ParseTasks(note)
+
+	for _, task := range tasks {
+		err = zet.ToggleTask(task)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "# Todo\n- [x] One\n  - [ ] Two\n" {
+		t.Errorf("after toggling = %q", content)
+	}
+
+	err = zet.ToggleTask(tasks[0])
+	if err == nil {
+		t.Error("ToggleTask() on a changed line should fail")
+	}
+}
@@ -0,0 +1,75 @@
+package zet
+
+import (
+	"fmt"
+	"strings"
+)
+
+const DefaultEmbedDepth = 5
+
+// ExpandEmbeds returns the body of note with every ![[Note]] and
+// ![[Note#Section]] embed replaced by the content of the embedded note
+// or section, expanded in turn up to depth levels deep. Embeds of
+// missing notes or sections and embeds beyond depth are left as they
+// are, and embeds that would recurse into a note being expanded are
+// replaced by a warning.
+func ExpandEmbeds(notes []*Note, note *Note, depth int) string {
+	return expandEmbeds(newResolver(notes), note, note.Body, depth, map[string]bool{})
+}
+
+func expandEmbeds(r *resolver, note *Note, body string, depth int, expanding map[string]bool) string {
+	if depth <= 0 {
+		return body
+	}
+	expanding[note.Path] = true
+	defer delete(expanding, note.Path)
+
+	var b strings.Builder
+	last := 0
+	for _, link := range ParseLinks(body) {
+		if !link.Embed {
+			continue
+		}
+		target := r.lookup(link.Target)
+		if target == nil {
+			continue
+		}
+
+		b.WriteString(body[last:link.Start])
+		last = link.End
+
+		if expanding[target.Path] {
+			fmt.Fprintf(&b, "> Embed cycle: %s", body[link.Start:link.End])
+			continue
+		}
+
+		content := embedContent(target, link)
+		if content == "" {
+			b.WriteString(body[link.Start:link.End])
+			continue
+		}
+		b.WriteString(expandEmbeds(r, target, content, depth-1, expanding))
+	}
+	b.WriteString(body[last:])
+	return b.String()
+}
+
+// embedContent returns the block or section of note that link refers
+// to, or without an anchor the whole note minus its frontmatter and
+// title.
+func embedContent(note *Note, link Link) string {
+	if link.Block != "" {
+		block, _ := BlockContent(note.Body, link.Block)
+		return strings.TrimSpace(block)
+	}
+	if link.Heading != "" {
+		section, _ := SectionContent(note.Body, link.Heading)
+		return strings.TrimSpace(section)
+	}
+
+	body := StripFrontmatter(note.Body)
+	if title := ParseTitle(body); title != "" {
+		body = strings.TrimPrefix(strings.TrimSpace(body), "# "+title)
+	}
+	return strings.TrimSpace(body)
+}
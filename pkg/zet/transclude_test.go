@@ -0,0 +1,59 @@
+package zet_test
+
+import (
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestExpandEmbeds(t *testing.T) {
+	notes := []*zet.Note{
+		{Title: "Main", Path: "/tmp/Main.md", Body: "# Main\n\nbefore\n![[Quote]]\nafter\n"},
+		{Title: "Quote", Path: "/tmp/Quote.md", Body: "---\ntags: [x]\n---\n# Quote\n\nTo be.\n![[Section Source#Part B]]\n"},
+		{Title: "Section Source", Path: "/tmp/Section Source.md", Body: "# Section Source\n## Part A\na\n## Part B\nb\n### Detail\nd\n## Part C\nc\n"},
+		{Title: "Loop", Path: "/tmp/Loop.md", Body: "loop ![[Loop]]"},
+		{Title: "Missing", Path: "/tmp/Missing.md", Body: "![[Nowhere]] and ![[Quote#Nowhere]]"},
+		{Title: "Deep", Path: "/tmp/Deep.md", Body: "![[Quote]]"},
+	}
+
+	tests := []struct {
+		name     string
+		note     *zet.Note
+		depth    int
+		expected string
+	}{
+		{
+			name:     "nested embeds with sections",
+			note:     notes[0],
+			depth:    zet.DefaultEmbedDepth,
+			expected: "# Main\n\nbefore\nTo be.\n## Part B\nb\n### Detail\nd\nafter\n",
+		},
+		{
+			name:     "cycle",
+			note:     notes[3],
+			depth:    zet.DefaultEmbedDepth,
+			expected: "loop > Embed cycle: ![[Loop]]",
+		},
+		{
+			name:     "missing targets are left alone",
+			note:     notes[4],
+			depth:    zet.DefaultEmbedDepth,
+			expected: "![[Nowhere]] and ![[Quote#Nowhere]]",
+		},
+		{
+			name:     "depth limit",
+			note:     notes[5],
+			depth:    1,
+			expected: "To be.\n![[Section Source#Part B]]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := zet.ExpandEmbeds(notes, tt.note, tt.depth)
+			if result != tt.expected {
+				t.Errorf("ExpandEmbeds() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package zet
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UsageEvent is something done to a note that counts towards its
+// frecency.
+type UsageEvent string
+
+const (
+	UsageOpened UsageEvent = "open"
+	UsageEdited UsageEvent = "edit"
+)
+
+// Usage is an entry of the usage log of a vault.
+type Usage struct {
+	Time  time.Time
+	Event UsageEvent
+	Path  string
+}
+
+const (
+	// frecencyHalfLife is how long it takes a use of a note to count
+	// for half as much.
+	frecencyHalfLife = 7 * 24 * time.Hour
+
+	// maxUsageEntries is how many entries the usage log keeps.
+	maxUsageEntries = 5000
+)
+
+var usageWeights = map[UsageEvent]float64{
+	UsageOpened: 1,
+	UsageEdited: 2,
+}
+
+// GetUsageLogPath returns the usage log of the vault in dir. It lives
+// in the XDG state directory rather than the vault so that it is never
+// synced.
+func GetUsageLogPath(dir string) string {
+	stateDir := os.Getenv("XDG_STATE_HOME")
+	if stateDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			home = os.TempDir()
+		}
+		stateDir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(stateDir, "zet", vaultKey(dir)+".log")
+}
+
+// FrecencyEnabled reports whether usage of the vault in dir is recorded
+// and used to rank notes, which is the case unless the frecency option
+// is set to a false value such as "off".
+func FrecencyEnabled(dir string) bool {
+	switch strings.ToLower(GetOption(dir, "frecency")) {
+	case "0", "false", "no", "off":
+		return false
+	}
+	return true
+}
+
+// RecordUsage adds event on the note at path to the usage log of its
+// vault, when frecency is enabled.
+func RecordUsage(path string, event UsageEvent) error {
+	dir := filepath.Dir(path)
+	if !FrecencyEnabled(dir) {
+		return nil
+	}
+
+	logPath := GetUsageLogPath(dir)
+	err := os.MkdirAll(filepath.Dir(logPath), 0700)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(file, "%d\t%s\t%s\n", time.Now().Unix(), event, filepath.Base(path))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	// Entries are about 40 bytes, so only read the log back to trim it
+	// once it is likely to be over the limit
+	info, err := os.Stat(logPath)
+	if err != nil || info.Size() < maxUsageEntries*40 {
+		return err
+	}
+	usage, err := ReadUsage(dir)
+	if err != nil || len(usage) <= maxUsageEntries {
+		return err
+	}
+
+	var b strings.Builder
+	for _, u := range usage[len(usage)-maxUsageEntries:] {
+		fmt.Fprintf(&b, "%d\t%s\t%s\n", u.Time.Unix(), u.Event, filepath.Base(u.Path))
+	}
+	return AtomicWriteFile(logPath, []byte(b.String()), 0600)
+}
+
+// renameUsage moves the usage of the note at oldPath to path, so that
+// a renamed note keeps its frecency.
+func renameUsage(oldPath, path string) error {
+	logPath := GetUsageLogPath(filepath.Dir(oldPath))
+	content, err := os.ReadFile(logPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	oldName, name := filepath.Base(oldPath), filepath.Base(path)
+	lines := strings.Split(string(content), "\n")
+	renamed := false
+	for i, line := range lines {
+		fields := strings.Split(line, "\t")
+		if len(fields) == 3 && fields[2] == oldName {
+			fields[2] = name
+			lines[i] = strings.Join(fields, "\t")
+			renamed = true
+		}
+	}
+	if !renamed {
+		return nil
+	}
+	return AtomicWriteFile(logPath, []byte(strings.Join(lines, "\n")), 0600)
+}
+
+// ReadUsage returns the usage log of the vault in dir, oldest first.
+func ReadUsage(dir string) ([]Usage, error) {
+	file, err := os.Open(GetUsageLogPath(dir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var usage []Usage
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		seconds, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		usage = append(usage, Usage{
+			Time:  time.Unix(seconds, 0),
+			Event: UsageEvent(fields[1]),
+			Path:  filepath.Join(dir, fields[2]),
+		})
+	}
+	return usage, scanner.Err()
+}
+
+// FrecencyScores returns the frecency of each note path in usage at
+// now: the weights of its uses, each halved for every week since.
+func FrecencyScores(usage []Usage, now time.Time) map[string]float64 {
+	scores := make(map[string]float64)
+	for _, u := range usage {
+		age := max(now.Sub(u.Time), 0)
+		scores[u.Path] += usageWeights[u.Event] * math.Pow(0.5, float64(age)/float64(frecencyHalfLife))
+	}
+	return scores
+}
+
+// RankByFrecency returns notes sorted by their frecency in the vault in
+// dir, most used first. Notes never used keep their order after the
+// rest, as do all notes when frecency is disabled.
+func RankByFrecency(dir string, notes []*Note) []*Note {
+	if !FrecencyEnabled(dir) {
+		return notes
+	}
+	usage, err := ReadUsage(dir)
+	if err != nil || len(usage) == 0 {
+		return notes
+	}
+
+	scores := FrecencyScores(usage, time.Now())
+	ranked := append([]*Note{}, notes...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i].Path] > scores[ranked[j].Path]
+	})
+	return ranked
+}
+
+// RecentNotes returns up to n of notes, most recently opened or edited
+// first, according to the usage log of the vault in dir.
+func RecentNotes(dir string, notes []*Note, n int) ([]*Note, error) {
+	usage, err := ReadUsage(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byPath := make(map[string]*Note)
+	for _, note := range notes {
+		byPath[note.Path] = note
+	}
+
+	var recent []*Note
+	seen := make(map[string]bool)
+	for i := len(usage) - 1; i >= 0 && len(recent) < n; i-- {
+		note := byPath[usage[i].Path]
+		if note == nil || seen[note.Path] {
+			continue
+		}
+		seen[note.Path] = true
+		recent = append(recent, note)
+	}
+	return recent, nil
+}
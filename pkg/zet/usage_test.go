@@ -0,0 +1,136 @@
+package zet_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestFrecencyScores(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+	usage := []zet.Usage{
+		{Time: now.Add(-2 * week), Event: zet.UsageOpened, Path: "Old.md"},
+		{Time: now.Add(-2 * week), Event: zet.UsageOpened, Path: "Old.md"},
+		{Time: now.Add(-2 * week), Event: zet.UsageOpened, Path: "Old.md"},
+		{Time: now.Add(-week), Event: zet.UsageEdited, Path: "Edited.md"},
+		{Time: now, Event: zet.UsageOpened, Path: "New.md"},
+	}
+
+	scores := zet.FrecencyScores(usage, now)
+
+	expected := map[string]float64{"Old.md": 0.75, "Edited.md": 1, "New.md": 1}
+	for path, want := range expected {
+		if got := scores[path]; got < want-1e-9 || got > want+1e-9 {
+			t.Errorf("score of %s = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestRecordUsage(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	if !strings.HasPrefix(zet.GetUsageLogPath(zetDir), os.Getenv("XDG_STATE_HOME")) {
+		t.Errorf("GetUsageLogPath() = %q, want it in XDG_STATE_HOME", zet.GetUsageLogPath(zetDir))
+	}
+
+	var notes []*zet.Note
+	for _, title := range []string{"Alpha", "Beta", "Gamma"} {
+		notes = append(notes, &zet.Note{Title: title, Path: filepath.Join(zetDir, title+".md")})
+	}
+
+	for _, record := range []struct {
+		note  *zet.Note
+		event zet.UsageEvent
+	}{
+		{notes[1], zet.UsageOpened},
+		{notes[2], zet.UsageOpened},
+		{notes[1], zet.UsageEdited},
+		{notes[2], zet.UsageOpened},
+	} {
+		if err := zet.RecordUsage(record.note.Path, record.event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names := func(notes []*zet.Note) string {
+		var titles []string
+		for _, note := range notes {
+			titles = append(titles, note.Title)
+		}
+		return strings.Join(titles, ",")
+	}
+
+	if got := names(zet.RankByFrecency(zetDir, notes)); got != "Beta,Gamma,Alpha" {
+		t.Errorf("RankByFrecency() = %s, want Beta,Gamma,Alpha", got)
+	}
+
+	recent, err := zet.RecentNotes(zetDir, notes, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(recent); got != "Gamma,Beta" {
+		t.Errorf("RecentNotes() = %s, want Gamma,Beta", got)
+	}
+	recent, err = zet.RecentNotes(zetDir, notes, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(recent); got != "Gamma" {
+		t.Errorf("RecentNotes(1) = %s, want Gamma", got)
+	}
+
+	err = zet.SetOption(zetDir, "frecency", "off")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := zet.RecordUsage(notes[0].Path, zet.UsageOpened); err != nil {
+		t.Fatal(err)
+	}
+	usage, err := zet.ReadUsage(zetDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usage) != 4 {
+		t.Errorf("RecordUsage() with frecency off recorded, %d entries", len(usage))
+	}
+	if got := names(zet.RankByFrecency(zetDir, notes)); got != "Alpha,Beta,Gamma" {
+		t.Errorf("RankByFrecency() with frecency off = %s, want Alpha,Beta,Gamma", got)
+	}
+}
+
+func TestRenameNoteKeepsUsage(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	path := filepath.Join(zetDir, "Draft.md")
+	err := os.WriteFile(path, []byte("# Draft\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	note, err := zet.ReadNote(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := zet.RecordUsage(path, zet.UsageEdited); err != nil {
+		t.Fatal(err)
+	}
+
+	renamed, err := zet.RenameNote(note, "Final")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recent, err := zet.RecentNotes(zetDir, []*zet.Note{renamed}, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recent) != 1 || recent[0] != renamed {
+		t.Errorf("RecentNotes() after rename = %v, want the renamed note", recent)
+	}
+}
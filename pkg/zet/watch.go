@@ -0,0 +1,206 @@
+package zet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+)
+
+type NoteEventKind string
+
+const (
+	NoteCreated NoteEventKind = "created"
+	NoteEdited  NoteEventKind = "edited"
+	NoteDeleted NoteEventKind = "deleted"
+	NoteRenamed NoteEventKind = "renamed"
+)
+
+type NoteEvent struct {
+	Kind    NoteEventKind
+	Path    string
+	OldPath string // Previous path for renames
+}
+
+type fileState struct {
+	ModTime time.Time
+	Size    int64
+	Hash    string
+}
+
+// Snapshot records the state of every note file in a directory so that
+// two snapshots can be compared to find what changed between them.
+type Snapshot map[string]fileState
+
+// TakeSnapshot scans dir, reusing hashes from prev for files whose size
+// and modification time have not changed.
+func TakeSnapshot(dir string, prev Snapshot) (Snapshot, error) {
+	files, err := ListNoteFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := make(Snapshot, len(files))
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		state := fileState{ModTime: info.ModTime(), Size: info.Size()}
+		if old, ok := prev[path]; ok && old.ModTime.Equal(state.ModTime) && old.Size == state.Size {
+			state.Hash = old.Hash
+		} else {
+			state.Hash, err = hashFile(path)
+			if err != nil {
+				continue
+			}
+		}
+		snap[path] = state
+	}
+
+	return snap, nil
+}
+
+// Diff returns the events that turn old into s. A note that disappears
+// while another with identical content appears is reported as a rename.
+func (s Snapshot) Diff(old Snapshot) []NoteEvent {
+	var created, deleted []string
+	var events []NoteEvent
+
+	for path, state := range s {
+		prev, ok := old[path]
+		if !ok {
+			created = append(created, path)
+		} else if prev.Hash != state.Hash {
+			events = append(events, NoteEvent{Kind: NoteEdited, Path: path})
+		}
+	}
+	for path := range old {
+		if _, ok := s[path]; !ok {
+			deleted = append(deleted, path)
+		}
+	}
+	sort.Strings(created)
+	sort.Strings(deleted)
+
+	renamed := make(map[string]bool)
+	for _, path := range created {
+		kind, oldPath := NoteCreated, ""
+		for _, gone := range deleted {
+			if !renamed[gone] && old[gone].Hash == s[path].Hash {
+				kind, oldPath = NoteRenamed, gone
+				renamed[gone] = true
+				break
+			}
+		}
+		events = append(events, NoteEvent{Kind: kind, Path: path, OldPath: oldPath})
+	}
+	for _, path := range deleted {
+		if !renamed[path] {
+			events = append(events, NoteEvent{Kind: NoteDeleted, Path: path})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Path < events[j].Path
+	})
+	return events
+}
+
+// Watch calls fn with the note events in dir each time it changes until
+// stop is closed. Bursts of changes, such as an editor saving through
+// a backup file, are coalesced so that fn sees only the net effect.
+func Watch(dir string, stop <-chan struct{}, fn func([]NoteEvent)) error {
+	return watch(dir, stop, nil, fn)
+}
+
+// watch is Watch, calling ready, when not nil, once the first snapshot
+// is taken so that later changes are sure to be seen.
+func watch(dir string, stop <-chan struct{}, ready func(), fn func([]NoteEvent)) error {
+	snap, err := TakeSnapshot(dir, nil)
+	if err != nil {
+		return err
+	}
+	if ready != nil {
+		ready()
+	}
+
+	changed := make(chan struct{}, 1)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- watchDir(dir, changed, stop)
+	}()
+
+	const settle = 200 * time.Millisecond
+	for {
+		select {
+		case <-stop:
+			return nil
+		case err := <-errc:
+			return err
+		case <-changed:
+		}
+
+		timer := time.NewTimer(settle)
+	drain:
+		for {
+			select {
+			case <-changed:
+				timer.Reset(settle)
+			case <-timer.C:
+				break drain
+			case <-stop:
+				timer.Stop()
+				return nil
+			}
+		}
+
+		next, err := TakeSnapshot(dir, snap)
+		if err != nil {
+			return err
+		}
+		if events := next.Diff(snap); len(events) > 0 {
+			fn(events)
+		}
+		snap = next
+	}
+}
+
+// Interrupted returns a channel that is closed when the process is
+// asked to stop with SIGINT or SIGTERM.
+func Interrupted() <-chan struct{} {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	stop := make(chan struct{})
+	go func() {
+		<-signals
+		signal.Stop(signals)
+		close(stop)
+	}()
+	return stop
+}
+
+func notify(changed chan<- struct{}) {
+	select {
+	case changed <- struct{}{}:
+	default:
+	}
+}
+
+func hashFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return hashContent(content), nil
+}
+
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
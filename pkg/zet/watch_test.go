@@ -0,0 +1,57 @@
+package zet_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arjungandhi/zet/pkg/zet"
+)
+
+func TestSnapshotDiff(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	write := func(name, content string) {
+		err := os.WriteFile(filepath.Join(zetDir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("Edited.md", "before")
+	write("Deleted.md", "gone soon")
+	write("Old Name.md", "moving")
+
+	before, err := zet.TakeSnapshot(zetDir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write("Edited.md", "after")
+	write("Created.md", "new")
+	os.Remove(filepath.Join(zetDir, "Deleted.md"))
+	os.Rename(filepath.Join(zetDir, "Old Name.md"), filepath.Join(zetDir, "New Name.md"))
+
+	after, err := zet.TakeSnapshot(zetDir, before)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []zet.NoteEvent{
+		{Kind: zet.NoteCreated, Path: filepath.Join(zetDir, "Created.md")},
+		{Kind: zet.NoteDeleted, Path: filepath.Join(zetDir, "Deleted.md")},
+		{Kind: zet.NoteEdited, Path: filepath.Join(zetDir, "Edited.md")},
+		{Kind: zet.NoteRenamed, Path: filepath.Join(zetDir, "New Name.md"), OldPath: filepath.Join(zetDir, "Old Name.md")},
+	}
+
+	events := after.Diff(before)
+	if len(events) != len(expected) {
+		t.Fatalf("Diff() returned %d events, want %d: %+v", len(events), len(expected), events)
+	}
+	for i := range events {
+		if events[i] != expected[i] {
+			t.Errorf("Diff()[%d] = %+v, want %+v", i, events[i], expected[i])
+		}
+	}
+}
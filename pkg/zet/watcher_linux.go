@@ -0,0 +1,69 @@
+//go:build linux
+
+package zet
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// watchDir signals changed whenever inotify reports activity on a note
+// file in dir. It fails if dir itself is removed or moved.
+func watchDir(dir string, changed chan<- struct{}, stop <-chan struct{}) error {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	mask := uint32(unix.IN_CREATE | unix.IN_CLOSE_WRITE | unix.IN_DELETE |
+		unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_MODIFY |
+		unix.IN_DELETE_SELF | unix.IN_MOVE_SELF)
+	if _, err := unix.InotifyAddWatch(fd, dir, mask); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+	for {
+		select {
+		case <-stop:
+			return nil
+		default:
+		}
+
+		n, err := unix.Poll(fds, 500)
+		if err == unix.EINTR || n == 0 {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		n, err = unix.Read(fd, buf)
+		if err == unix.EAGAIN || err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+			event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + unix.SizeofInotifyEvent
+			name := string(bytes.TrimRight(buf[nameStart:nameStart+int(event.Len)], "\x00"))
+			offset = nameStart + int(event.Len)
+
+			if event.Mask&(unix.IN_DELETE_SELF|unix.IN_MOVE_SELF|unix.IN_IGNORED) != 0 {
+				return fmt.Errorf("%s was removed or moved", dir)
+			}
+			if event.Mask&unix.IN_Q_OVERFLOW != 0 || strings.HasSuffix(name, ".md") {
+				notify(changed)
+			}
+		}
+	}
+}
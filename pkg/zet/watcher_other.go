@@ -0,0 +1,26 @@
+//go:build !linux
+
+package zet
+
+import (
+	"os"
+	"time"
+)
+
+// watchDir signals changed periodically so that callers rescan dir on
+// platforms without inotify support. It fails once dir is gone.
+func watchDir(dir string, changed chan<- struct{}, stop <-chan struct{}) error {
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+			if _, err := os.Stat(dir); err != nil {
+				return err
+			}
+			notify(changed)
+		}
+	}
+}
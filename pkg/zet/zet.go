@@ -1,48 +1,174 @@
 package zet
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
-
-	bonzai "github.com/rwxrob/bonzai/z"
+	"sort"
+	"strings"
 )
 
 type Note struct {
-	Title string
-	Path  string
-	Body  string
+	Title string // Title from the leading H1, or the filename without .md
+	Path  string // Full path to .md file
+	Body  string // Complete file contents
+}
+
+// Name returns the filename of the note without the .md extension.
+func (n *Note) Name() string {
+	return strings.TrimSuffix(filepath.Base(n.Path), ".md")
+}
+
+// CollisionError reports that a new title maps onto the file of an
+// existing note with a different title.
+type CollisionError struct {
+	Title    string
+	Existing *Note
 }
 
+func (e *CollisionError) Error() string {
+	return fmt.Sprintf("%q collides with existing note %q @ %s", e.Title, e.Existing.Title, e.Existing.Path)
+}
+
+// CreateOrEditNote returns the path of the note titled title, or with
+// title as an alias, creating it if needed. If title would be stored in
+// the file of a note with a different title it returns a
+// *CollisionError instead.
 func CreateOrEditNote(title string) (string, error) {
 	dir, err := GetZetDir()
 	if err != nil {
 		return "", err
 	}
 
-	sanitized := SanitizeFilename(title)
-	path := filepath.Join(dir, sanitized+".md")
+	filename, err := NoteFilename(dir, title)
+	if err != nil {
+		return "", err
+	}
+	title = strings.TrimSpace(title)
+
+	notes, err := scanNotes(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, note := range notes {
+		if note.Title == title {
+			return note.Path, nil
+		}
+	}
+	for _, note := range notes {
+		for _, alias := range NoteAliases(note) {
+			if strings.EqualFold(alias, title) {
+				return note.Path, nil
+			}
+		}
+	}
 
-	if !NoteExists(dir, title) {
-		err = WriteNote(dir, title, "")
+	if path, ok := FindNoteFile(dir, filename); ok {
+		existing, err := ReadNote(path)
 		if err != nil {
 			return "", err
 		}
+		// Either form of the note's name opens it; another title that
+		// only sanitizes to the same filename collides with it
+		if existing.Title != title && existing.Name() != title {
+			return "", &CollisionError{Title: title, Existing: existing}
+		}
+		return path, nil
+	}
+
+	return createNote(dir, title, filename)
+}
+
+// ResolveCollision asks whether to open the existing note, create the
+// new one under a disambiguated filename, or abort, and returns the
+// path of the note to edit.
+func ResolveCollision(collision *CollisionError) (string, error) {
+	fmt.Fprintf(os.Stderr, "%v\n", collision)
+	choice, err := Choose("Open the existing note, create a new file, or abort?", "open", "new", "abort")
+	if err != nil {
+		return "", err
+	}
+
+	switch choice {
+	case 0:
+		return collision.Existing.Path, nil
+	case 1:
+		dir := filepath.Dir(collision.Existing.Path)
+		filename := DisambiguateFilename(dir, filepath.Base(collision.Existing.Path))
+		return createNote(dir, collision.Title, filename)
+	default:
+		return "", fmt.Errorf("aborted")
+	}
+}
+
+func createNote(dir, title, filename string) (string, error) {
+	path := filepath.Join(dir, filename)
+	err := AtomicWriteFile(path, []byte("# "+title+"\n\n"), 0644)
+	if err != nil {
+		return "", err
+	}
+
+	fireHook(HookCreated, path, title, "")
+	return path, nil
+}
+
+// AddToNote inserts content into the note titled title, creating it if
+// needed, without opening an editor. It returns the path of the note.
+func AddToNote(title, content string, opts InsertOptions) (string, error) {
+	path, err := CreateOrEditNote(title)
+	if err != nil {
+		return "", err
+	}
+
+	note, err := ReadNote(path)
+	if err != nil {
+		return "", err
+	}
+
+	body := InsertContent(note.Body, content, opts)
+	err = AtomicWriteFile(path, []byte(body), 0644)
+	if err != nil {
+		return "", err
 	}
 
+	RefreshDaemon(filepath.Dir(path), path)
+	fireHook(HookEdited, path, note.Title, "")
 	return path, nil
 }
 
+// DeleteNote removes note unless its pre-delete hook fails.
 func DeleteNote(note *Note) error {
-	return os.Remove(note.Path)
+	err := RunHook(HookPreDelete, note.Path, note.Title, "")
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(note.Path)
+	if err != nil {
+		return err
+	}
+
+	fireHook(HookDeleted, note.Path, note.Title, "")
+	return nil
 }
 
+// ListNotes returns every note in the vault, asking the daemon when one
+// is running and scanning the directory otherwise.
 func ListNotes() ([]*Note, error) {
 	dir, err := GetZetDir()
 	if err != nil {
 		return nil, err
 	}
 
+	if resp, err := daemonCall(dir, daemonRequest{Op: "list"}); err == nil {
+		return resp.Notes, nil
+	}
+
+	return scanNotes(dir)
+}
+
+func scanNotes(dir string) ([]*Note, error) {
 	files, err := ListNoteFiles(dir)
 	if err != nil {
 		return nil, err
@@ -60,19 +186,195 @@ func ListNotes() ([]*Note, error) {
 	return notes, nil
 }
 
+// SearchNotes returns the notes matching every term of query.
+func SearchNotes(query string) ([]*Note, error) {
+	dir, err := GetZetDir()
+	if err != nil {
+		return nil, err
+	}
+
+	if resp, err := daemonCall(dir, daemonRequest{Op: "search", Args: []string{query}}); err == nil {
+		return resp.Notes, nil
+	}
+
+	notes, err := scanNotes(dir)
+	if err != nil {
+		return nil, err
+	}
+	return BuildIndex(notes).Search(query), nil
+}
+
+// NoteBacklinks returns the notes that link to note.
+func NoteBacklinks(note *Note) ([]*Note, error) {
+	dir, err := GetZetDir()
+	if err != nil {
+		return nil, err
+	}
+
+	if resp, err := daemonCall(dir, daemonRequest{Op: "backlinks", Args: []string{note.Path}}); err == nil {
+		return resp.Notes, nil
+	}
+
+	notes, err := scanNotes(dir)
+	if err != nil {
+		return nil, err
+	}
+	return Backlinks(notes, note), nil
+}
+
+// SortNotes orders notes by path, matching the order of ListNoteFiles.
+func SortNotes(notes []*Note) {
+	sort.Slice(notes, func(i, j int) bool {
+		return notes[i].Path < notes[j].Path
+	})
+}
+
+// OpenNote edits the note picked with fzf for searchTerm. A searchTerm
+// naming a note with a heading or block anchor, such as "Note#Heading",
+// "Note#^block-id" or "[[Note#Heading]]", opens that note at the anchor.
 func OpenNote(searchTerm string) error {
 	notes, err := ListNotes()
 	if err != nil {
 		return err
 	}
 
+	if note, line := ResolveAnchor(notes, searchTerm); note != nil {
+		return EditAndFinish(note.Path, line, 1)
+	}
+
 	note, err := FindNote(notes, searchTerm)
 	if err != nil {
 		return err
 	}
 
-	editor := GetEditor()
-	return bonzai.SysExec(editor, note.Path)
+	return EditAndFinish(note.Path, 0, 0)
+}
+
+// ResolveAnchor returns the note and line that ref, a link target with
+// a heading or block anchor, refers to. It returns nil unless ref names
+// an existing anchor and is not itself the title of a note.
+func ResolveAnchor(notes []*Note, ref string) (*Note, int) {
+	ref = strings.TrimSpace(ref)
+	ref = strings.TrimPrefix(strings.TrimSuffix(ref, "]]"), "[[")
+	if !strings.Contains(ref, "#") || LookupNote(notes, ref) != nil {
+		return nil, 0
+	}
+
+	links := ParseLinks("[[" + ref + "]]")
+	if len(links) != 1 {
+		return nil, 0
+	}
+
+	note := LookupNote(notes, links[0].Target)
+	if note == nil {
+		return nil, 0
+	}
+	line, ok := AnchorLine(note.Body, links[0])
+	if !ok {
+		return nil, 0
+	}
+	return note, line
+}
+
+// RenameHeadingLinks points the links to heading oldHeading of note in
+// every other note of the vault at newHeading, returning how many notes
+// changed.
+func RenameHeadingLinks(note *Note, oldHeading, newHeading string) (int, error) {
+	notes, err := scanNotes(filepath.Dir(note.Path))
+	if err != nil {
+		return 0, err
+	}
+	r := newResolver(notes)
+
+	changed := 0
+	for _, other := range notes {
+		body := EditLinks(other.Body, func(link Link, text string) (string, bool) {
+			target := r.lookup(link.Target)
+			if link.Target == "" && other.Path == note.Path {
+				target = other
+			}
+			if target == nil || target.Path != note.Path || !strings.EqualFold(link.Heading, oldHeading) {
+				return "", false
+			}
+			link.Heading = newHeading
+			if link.Target == "" {
+				return FormatWikiLink(link), true
+			}
+			return FormatLink(text, link, target), true
+		})
+		if body == other.Body {
+			continue
+		}
+		err = AtomicWriteFile(other.Path, []byte(body), 0644)
+		if err != nil {
+			return changed, err
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+// RenameNote moves note to the file for title and points the links of
+// every other note in the vault at it. Its pin and usage history are
+// kept.
+func RenameNote(note *Note, title string) (*Note, error) {
+	dir := filepath.Dir(note.Path)
+	filename, err := NoteFilename(dir, title)
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, filename)
+	if existing, ok := FindNoteFile(dir, filename); ok && existing != note.Path {
+		return nil, fmt.Errorf("cannot rename to %s: file exists", filename)
+	}
+
+	notes, err := scanNotes(dir)
+	if err != nil {
+		return nil, err
+	}
+	r := newResolver(notes)
+
+	// Links by alias still resolve after the rename, so they are kept
+	aliases := make(map[string]bool)
+	for _, alias := range NoteAliases(note) {
+		aliases[strings.ToLower(alias)] = true
+	}
+
+	err = os.Rename(note.Path, path)
+	if err != nil {
+		return nil, err
+	}
+
+	renamed := &Note{Title: title, Path: path, Body: note.Body}
+	for _, other := range notes {
+		if other.Path == note.Path {
+			continue
+		}
+		body := RewriteLinks(other.Body, func(link Link) bool {
+			target := r.lookup(link.Target)
+			return target != nil && target.Path == note.Path && !aliases[strings.ToLower(link.Target)]
+		}, renamed)
+		if body == other.Body {
+			continue
+		}
+		err = AtomicWriteFile(other.Path, []byte(body), 0644)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = renamePin(note.Path, path)
+	if err != nil {
+		return nil, err
+	}
+	err = renameUsage(note.Path, path)
+	if err != nil {
+		return nil, err
+	}
+
+	fireHook(HookRenamed, path, title, note.Path)
+	return renamed, nil
 }
 
 func RenderNote(searchTerm string) error {
@@ -86,8 +388,12 @@ func RenderNote(searchTerm string) error {
 		return err
 	}
 
+	RecordUsage(note.Path, UsageOpened)
+	expanded := ExpandNote(notes, note)
+
 	renderer := GetRenderer()
-	cmd := exec.Command(renderer, note.Path)
+	cmd := exec.Command(renderer, "-")
+	cmd.Stdin = strings.NewReader(expanded)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
@@ -1,6 +1,7 @@
 package zet_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -8,6 +9,19 @@ import (
 	"github.com/arjungandhi/zet/pkg/zet"
 )
 
+// TestMain keeps the usage logs written by the tests out of the user's
+// state directory.
+func TestMain(m *testing.M) {
+	stateDir, err := os.MkdirTemp("", "zet-state-*")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_STATE_HOME", stateDir)
+	code := m.Run()
+	os.RemoveAll(stateDir)
+	os.Exit(code)
+}
+
 func TestCreateOrEditNote_Create(t *testing.T) {
 	zetDir := ZetDir(t)
 	defer Cleanup(t, zetDir)
@@ -40,6 +54,44 @@ func TestCreateOrEditNote_Create(t *testing.T) {
 	}
 }
 
+func TestCreateOrEditNote_PreservesTitle(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	originalZetDir := os.Getenv("ZETDIR")
+	os.Setenv("ZETDIR", zetDir)
+	defer func() {
+		if originalZetDir != "" {
+			os.Setenv("ZETDIR", originalZetDir)
+		} else {
+			os.Unsetenv("ZETDIR")
+		}
+	}()
+
+	notePath, err := zet.CreateOrEditNote("What's the plan?")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	note, err := zet.ReadNote(notePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if note.Title != "What's the plan?" {
+		t.Errorf("note.Title = %q, want %q", note.Title, "What's the plan?")
+	}
+
+	samePath, err := zet.CreateOrEditNote("Whats the plan")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if samePath != notePath {
+		t.Errorf("CreateOrEditNote() by filename = %q, want %q", samePath, notePath)
+	}
+}
+
 func TestCreateOrEditNote_Edit(t *testing.T) {
 	zetDir := ZetDir(t)
 	defer Cleanup(t, zetDir)
@@ -81,6 +133,168 @@ func TestCreateOrEditNote_Edit(t *testing.T) {
 	}
 }
 
+func TestCreateOrEditNote_Collision(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	originalZetDir := os.Getenv("ZETDIR")
+	os.Setenv("ZETDIR", zetDir)
+	defer func() {
+		if originalZetDir != "" {
+			os.Setenv("ZETDIR", originalZetDir)
+		} else {
+			os.Unsetenv("ZETDIR")
+		}
+	}()
+
+	_, err := zet.CreateOrEditNote("C")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = zet.CreateOrEditNote("C++")
+	var collision *zet.CollisionError
+	if !errors.As(err, &collision) {
+		t.Fatalf("CreateOrEditNote() error = %v, want *CollisionError", err)
+	}
+
+	if collision.Existing.Title != "C" {
+		t.Errorf("collision.Existing.Title = %q, want %q", collision.Existing.Title, "C")
+	}
+
+	// A note stored under a disambiguated filename is found by title
+	disambiguated := filepath.Join(zetDir, zet.DisambiguateFilename(zetDir, "C.md"))
+	err = os.WriteFile(disambiguated, []byte("# C++\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := zet.CreateOrEditNote("C++")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(zetDir, "C 2.md") {
+		t.Errorf("CreateOrEditNote() = %q, want %q", path, filepath.Join(zetDir, "C 2.md"))
+	}
+
+	// The file stem of a note with another title opens that note
+	err = os.Remove(filepath.Join(zetDir, "C.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(zetDir, "C.md"), []byte("# C++\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Remove(disambiguated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err = zet.CreateOrEditNote("C")
+	if err != nil {
+		t.Fatalf("CreateOrEditNote(\"C\") with C++ in C.md error = %v", err)
+	}
+	if path != filepath.Join(zetDir, "C.md") {
+		t.Errorf("CreateOrEditNote(\"C\") = %q, want %q", path, filepath.Join(zetDir, "C.md"))
+	}
+}
+
+func TestCreateOrEditNote_CaseInsensitive(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	originalZetDir := os.Getenv("ZETDIR")
+	os.Setenv("ZETDIR", zetDir)
+	defer func() {
+		if originalZetDir != "" {
+			os.Setenv("ZETDIR", originalZetDir)
+		} else {
+			os.Unsetenv("ZETDIR")
+		}
+	}()
+
+	_, err := zet.CreateOrEditNote("Go Notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = zet.SetOption(zetDir, "case-insensitive", "true")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = zet.CreateOrEditNote("go notes")
+	var collision *zet.CollisionError
+	if !errors.As(err, &collision) {
+		t.Errorf("CreateOrEditNote() error = %v, want *CollisionError", err)
+	}
+}
+
+func TestResolveAnchor(t *testing.T) {
+	notes := []*zet.Note{
+		{Title: "Paper", Path: "/tmp/Paper.md", Body: "# Paper\n\n## Method\nquote ^q1\n"},
+		{Title: "C#", Path: "/tmp/C.md", Body: "# C#\n"},
+	}
+
+	tests := []struct {
+		ref  string
+		note *zet.Note
+		line int
+	}{
+		{ref: "Paper#Method", note: notes[0], line: 3},
+		{ref: "[[Paper#^q1]]", note: notes[0], line: 4},
+		{ref: "Paper#Missing", note: nil},
+		{ref: "C#", note: nil},
+		{ref: "Paper", note: nil},
+	}
+
+	for _, tt := range tests {
+		note, line := zet.ResolveAnchor(notes, tt.ref)
+		if note != tt.note || line != tt.line {
+			t.Errorf("ResolveAnchor(%q) = %v, %d, want %v, %d", tt.ref, note, line, tt.note, tt.line)
+		}
+	}
+}
+
+func TestRenameHeadingLinks(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	paperPath := filepath.Join(zetDir, "Paper.md")
+	err := os.WriteFile(paperPath, []byte("# Paper\n## Findings\nsee [[#results]]"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	citerPath := filepath.Join(zetDir, "Citer.md")
+	err = os.WriteFile(citerPath, []byte("[[Paper#Results|r]] [p](Paper.md#Results) [[Paper#Method]]"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	note, err := zet.ReadNote(paperPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := zet.RenameHeadingLinks(note, "Results", "Findings")
+	if err != nil {
+		t.Fatalf("RenameHeadingLinks() error = %v", err)
+	}
+	if changed != 2 {
+		t.Errorf("RenameHeadingLinks() changed %d notes, want 2", changed)
+	}
+
+	content, _ := os.ReadFile(citerPath)
+	if string(content) != "[[Paper#Findings|r]] [p](Paper.md#Findings) [[Paper#Method]]" {
+		t.Errorf("citing note = %q", string(content))
+	}
+	content, _ = os.ReadFile(paperPath)
+	if string(content) != "# Paper\n## Findings\nsee [[#Findings]]" {
+		t.Errorf("note itself = %q", string(content))
+	}
+}
+
 func TestDeleteNote(t *testing.T) {
 	zetDir := ZetDir(t)
 	defer Cleanup(t, zetDir)
@@ -191,3 +405,40 @@ func Cleanup(t *testing.T, dir string) {
 		t.Fatal(err)
 	}
 }
+
+func TestCreateOrEditNote_Alias(t *testing.T) {
+	zetDir := ZetDir(t)
+	defer Cleanup(t, zetDir)
+
+	originalZetDir := os.Getenv("ZETDIR")
+	os.Setenv("ZETDIR", zetDir)
+	defer func() {
+		if originalZetDir != "" {
+			os.Setenv("ZETDIR", originalZetDir)
+		} else {
+			os.Unsetenv("ZETDIR")
+		}
+	}()
+
+	notePath := filepath.Join(zetDir, "Command Line Interface.md")
+	err := os.WriteFile(notePath, []byte("# Command Line Interface\naliases: CLI\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	returnedPath, err := zet.CreateOrEditNote("cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if returnedPath != notePath {
+		t.Errorf("CreateOrEditNote() by alias = %q, want %q", returnedPath, notePath)
+	}
+
+	files, err := zet.ListNoteFiles(zetDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("CreateOrEditNote() by alias created a note: %v", files)
+	}
+}